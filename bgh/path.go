@@ -3,6 +3,8 @@ package bgh
 import (
 	"fmt"
 	"net/url"
+	"strconv"
+	"strings"
 )
 
 var InvalidURLError = fmt.Errorf("URL does not contain all required query parameters")
@@ -27,3 +29,29 @@ func PathFromURL(u string) (string, error) {
 
 	return fmt.Sprintf("judgements/%s/%s/%s_%s_%s.pdf", court, date, nr, anz, pos), nil
 }
+
+var InvalidPathError = fmt.Errorf("path was not created from a BGH URL")
+
+// What the crawler knows about a decision, encoded in the path created by PathFromURL
+type PathMetadata struct {
+	Court string
+	Year  int
+}
+
+func MetadataFromPath(path string) (*PathMetadata, error) {
+	segments := strings.Split(path, "/")
+
+	if len(segments) != 4 || segments[0] != "judgements" {
+		return nil, InvalidPathError
+	}
+
+	year, err := strconv.Atoi(segments[2])
+	if err != nil {
+		return nil, InvalidPathError
+	}
+
+	return &PathMetadata{
+		Court: segments[1],
+		Year:  year,
+	}, nil
+}
@@ -45,3 +45,18 @@ func Test_PathFromURL(t *testing.T) {
 		assert.ErrorIs(t, err, InvalidURLError, "Should return an `InvalidURLError` error")
 	})
 }
+
+func Test_MetadataFromPath(t *testing.T) {
+	t.Run("Returns the court and year of the path", func(t *testing.T) {
+		actual, err := MetadataFromPath("judgements/bgh/2021/117424_3571_2950.pdf")
+
+		assert.NoError(t, err, "Should not return an error")
+		assert.Equal(t, &PathMetadata{Court: "bgh", Year: 2021}, actual, "Should return the court and year")
+	})
+
+	t.Run("Returns error for paths not created from a BGH URL", func(t *testing.T) {
+		_, err := MetadataFromPath("uploads/judgement.pdf")
+
+		assert.ErrorIs(t, err, InvalidPathError, "Should return an `InvalidPathError` error")
+	})
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+
+	"github.com/JuliusMoehring/court-judgment-finder-crawler/embedder"
+	"github.com/JuliusMoehring/court-judgment-finder-crawler/logger"
+)
+
+// Returns the price in US dollars per million tokens from EMBEDDING_PRICE_PER_MILLION_TOKENS, defaults to the
+// price of the OpenAI model, also for Azure. Other providers are free unless a price is configured.
+func embeddingPrice(model string) (float64, error) {
+	if value := os.Getenv("EMBEDDING_PRICE_PER_MILLION_TOKENS"); value != "" {
+		price, err := strconv.ParseFloat(value, 64)
+		if err != nil || price < 0 {
+			return 0, fmt.Errorf("invalid EMBEDDING_PRICE_PER_MILLION_TOKENS: '%s'", value)
+		}
+
+		return price, nil
+	}
+
+	if !isOpenAIProvider(os.Getenv("EMBEDDING_PROVIDER")) {
+		return 0, nil
+	}
+
+	price, _ := embedder.OpenAIPricePerMillionTokens(model)
+
+	return price, nil
+}
+
+func embeddingCost(tokens int, pricePerMillionTokens float64) float64 {
+	return float64(tokens) * pricePerMillionTokens / 1_000_000
+}
+
+// Limits the tokens sent to the embedder to EMBEDDING_BUDGET_TOKENS or EMBEDDING_BUDGET_USD, the embedder is
+// returned as is if neither is set. The returned function logs the spent tokens and what was left out.
+func withEmbeddingBudget(logger logger.Logger, e embedder.Embedder, counter func(text string) int) (embedder.Embedder, func(), error) {
+	tokensValue := os.Getenv("EMBEDDING_BUDGET_TOKENS")
+	usdValue := os.Getenv("EMBEDDING_BUDGET_USD")
+
+	if tokensValue == "" && usdValue == "" {
+		return e, func() {}, nil
+	}
+
+	if tokensValue != "" && usdValue != "" {
+		return nil, nil, fmt.Errorf("EMBEDDING_BUDGET_TOKENS and EMBEDDING_BUDGET_USD cannot be combined")
+	}
+
+	price, err := embeddingPrice(e.Model())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var limit int
+
+	if tokensValue != "" {
+		limit, err = strconv.Atoi(tokensValue)
+		if err != nil || limit <= 0 {
+			return nil, nil, fmt.Errorf("invalid EMBEDDING_BUDGET_TOKENS: '%s'", tokensValue)
+		}
+	} else {
+		usd, err := strconv.ParseFloat(usdValue, 64)
+		if err != nil || usd <= 0 {
+			return nil, nil, fmt.Errorf("invalid EMBEDDING_BUDGET_USD: '%s'", usdValue)
+		}
+
+		if price == 0 {
+			return nil, nil, fmt.Errorf("EMBEDDING_BUDGET_USD requires a price, set EMBEDDING_PRICE_PER_MILLION_TOKENS")
+		}
+
+		limit = int(math.Floor(usd / price * 1_000_000))
+	}
+
+	budgeted := embedder.NewBudgetedEmbedder(e, counter, limit)
+
+	return budgeted, func() {
+		report := budgeted.Report()
+		logger.Infof("main", "embedding budget: spent %d of %d tokens ($%.4f)", report.SpentTokens, report.LimitTokens, embeddingCost(report.SpentTokens, price))
+
+		if report.RejectedTexts > 0 {
+			logger.Warnf("main", "embedding budget exceeded: %d texts with %d tokens ($%.4f) were not embedded and remain pending", report.RejectedTexts, report.RejectedTokens, embeddingCost(report.RejectedTokens, price))
+		}
+	}, nil
+}
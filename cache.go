@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/JuliusMoehring/court-judgment-finder-crawler/embedder"
+	filestorage "github.com/JuliusMoehring/court-judgment-finder-crawler/file-storage"
+	"github.com/JuliusMoehring/court-judgment-finder-crawler/logger"
+	vectorstore "github.com/JuliusMoehring/court-judgment-finder-crawler/vector-store"
+)
+
+const DEFAULT_EMBEDDING_CACHE_DIR = "embedding-cache"
+
+// Creates the embedding cache configured by EMBEDDING_CACHE (postgres or file), returns nil if it is not set.
+// The file cache is stored in EMBEDDING_CACHE_DIR. The returned function releases the cache.
+func newEmbeddingCache(ctx context.Context, logger logger.Logger) (embedder.Cache, func(), error) {
+	switch backend := os.Getenv("EMBEDDING_CACHE"); backend {
+	case "":
+		return nil, func() {}, nil
+	case "postgres":
+		cache := vectorstore.NewPostgresEmbeddingCache(ctx, logger)
+		return cache, cache.Close, nil
+	case "file":
+		dir := os.Getenv("EMBEDDING_CACHE_DIR")
+		if dir == "" {
+			dir = DEFAULT_EMBEDDING_CACHE_DIR
+		}
+
+		return embedder.NewFileCache(filestorage.NewLocalFileStorage(dir)), func() {}, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown embedding cache: '%s'", backend)
+	}
+}
+
+// Wraps the embedder with the configured embedding cache. Embeddings are cached per policy of the context window
+// if the embedder limits it. The returned function logs the hits and misses of the cache and releases it.
+func withEmbeddingCache(ctx context.Context, logger logger.Logger, e embedder.Embedder) (embedder.Embedder, func(), error) {
+	cache, closeCache, err := newEmbeddingCache(ctx, logger)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if cache == nil {
+		return e, closeCache, nil
+	}
+
+	policy := ""
+
+	if limited, ok := e.(*embedder.ContextWindowEmbedder); ok {
+		policy = limited.Policy()
+	}
+
+	cached := embedder.NewCachedEmbedder(logger, e, cache, policy)
+
+	return cached, func() {
+		stats := cached.Stats()
+		logger.Infof("main", "embedding cache: %d hits, %d misses", stats.Hits, stats.Misses)
+
+		closeCache()
+	}, nil
+}
+
+// Returns the hits and misses of the embedding cache, false if the embedder is not cached
+func embeddingCacheStats(e embedder.Embedder) (embedder.CacheStats, bool) {
+	cached, ok := e.(*embedder.CachedEmbedder)
+	if !ok {
+		return embedder.CacheStats{}, false
+	}
+
+	return cached.Stats(), true
+}
+
+// Evicts entries of the embedding cache that were not used for a while or belong to another model
+func runEvictEmbeddingCache(ctx context.Context, logger logger.Logger, args []string) error {
+	flags := flag.NewFlagSet("evict-embedding-cache", flag.ExitOnError)
+
+	unusedFor := flags.Duration("unused-for", 0, "evict entries that were not used for the duration, e.g. 720h")
+	otherModels := flags.Bool("other-models", false, "evict entries of all models except the configured one")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *unusedFor <= 0 && !*otherModels {
+		return fmt.Errorf("usage: evict-embedding-cache [-unused-for <duration>] [-other-models]")
+	}
+
+	cache, closeCache, err := newEmbeddingCache(ctx, logger)
+	if err != nil {
+		return err
+	}
+	defer closeCache()
+
+	if cache == nil {
+		return fmt.Errorf("no embedding cache configured, set EMBEDDING_CACHE")
+	}
+
+	params := embedder.EvictParams{}
+
+	if *unusedFor > 0 {
+		params.UnusedSince = time.Now().Add(-*unusedFor)
+	}
+
+	if *otherModels {
+		embedder, closeEmbedder, err := newEmbedder(ctx, logger, embedder.EstimateTokens, 0)
+		if err != nil {
+			return err
+		}
+		defer closeEmbedder()
+
+		params.KeepModel = embedder.Model()
+		params.KeepDimensions = embedder.Dimensions()
+	}
+
+	evicted, err := cache.Evict(ctx, params)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%d entries evicted\n", evicted)
+
+	return nil
+}
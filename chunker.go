@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/JuliusMoehring/court-judgment-finder-crawler/chunking"
+)
+
+const (
+	DEFAULT_CHUNK_SIZE    = 512
+	DEFAULT_CHUNK_OVERLAP = 64
+)
+
+// Creates the chunker configured by CHUNKING_STRATEGY (tokens, sentences or margin-numbers), CHUNK_SIZE and
+// CHUNK_OVERLAP (both in tokens), defaults to margin-numbers. The overlap only applies to the tokens strategy.
+// Chunks are sized with the counter the embedding budget and the context window use.
+func newChunker(counter func(text string) int) (chunking.Chunker, error) {
+	size := DEFAULT_CHUNK_SIZE
+	overlap := DEFAULT_CHUNK_OVERLAP
+
+	if value := os.Getenv("CHUNK_SIZE"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			return nil, fmt.Errorf("invalid CHUNK_SIZE: '%s'", value)
+		}
+
+		size = parsed
+	}
+
+	if value := os.Getenv("CHUNK_OVERLAP"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 || parsed >= size {
+			return nil, fmt.Errorf("invalid CHUNK_OVERLAP: '%s', must be smaller than the chunk size", value)
+		}
+
+		overlap = parsed
+	}
+
+	switch strategy := os.Getenv("CHUNKING_STRATEGY"); strategy {
+	case "", chunking.STRATEGY_MARGIN_NUMBERS:
+		return chunking.NewMarginNumberChunker(counter, size), nil
+	case chunking.STRATEGY_SENTENCES:
+		return chunking.NewSentenceChunker(counter, size), nil
+	case chunking.STRATEGY_TOKENS:
+		return chunking.NewTokenChunker(counter, size, overlap), nil
+	default:
+		return nil, fmt.Errorf("unknown chunking strategy: '%s'", strategy)
+	}
+}
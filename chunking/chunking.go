@@ -0,0 +1,146 @@
+package chunking
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/JuliusMoehring/court-judgment-finder-crawler/pdf"
+)
+
+// Counts the tokens of a text the way the embedding model does
+type TokenCounter func(text string) int
+
+type Chunk struct {
+	Text string
+	// Byte offsets in the normalized text of the document, with pages separated by form feeds
+	Start int
+	End   int
+	// Pages the chunk spans, starting at 1
+	StartPage int
+	EndPage   int
+	// Randnummern the chunk spans, zero before the first Randnummer of the document
+	StartMarginNumber int
+	EndMarginNumber   int
+}
+
+type Chunker interface {
+	// Splits the normalized pages of a document into chunks in order of their appearance
+	Chunk(pages []pdf.NormalizedPage) []Chunk
+	// Name of the strategy, stored with every chunk
+	Strategy() string
+}
+
+type marginNumber struct {
+	number int
+	offset int
+}
+
+// The normalized text of a document with the offsets of its pages and Randnummern
+type document struct {
+	text          string
+	pageStarts    []int
+	pageNumbers   []int
+	marginNumbers []marginNumber
+}
+
+func newDocument(pages []pdf.NormalizedPage) *document {
+	doc := &document{
+		text: pdf.NormalizedText(pages),
+	}
+
+	offset := 0
+
+	for _, page := range pages {
+		doc.pageStarts = append(doc.pageStarts, offset)
+		doc.pageNumbers = append(doc.pageNumbers, page.Page)
+
+		for _, number := range page.MarginNumbers {
+			doc.marginNumbers = append(doc.marginNumbers, marginNumber{number: number.Number, offset: offset + number.Offset})
+		}
+
+		// Pages are separated by a single form feed
+		offset += len(page.Text) + 1
+	}
+
+	return doc
+}
+
+// Returns the page containing the byte offset
+func (d *document) page(offset int) int {
+	i := sort.Search(len(d.pageStarts), func(i int) bool {
+		return d.pageStarts[i] > offset
+	})
+
+	if i == 0 {
+		return 0
+	}
+
+	return d.pageNumbers[i-1]
+}
+
+// Returns the Randnummer of the paragraph containing the byte offset
+func (d *document) marginNumber(offset int) int {
+	i := sort.Search(len(d.marginNumbers), func(i int) bool {
+		return d.marginNumbers[i].offset > offset
+	})
+
+	if i == 0 {
+		return 0
+	}
+
+	return d.marginNumbers[i-1].number
+}
+
+type textRange struct {
+	start int
+	end   int
+}
+
+// Shrinks the range so it neither starts nor ends with whitespace
+func (d *document) trim(r textRange) textRange {
+	for r.start < r.end {
+		char, size := utf8.DecodeRuneInString(d.text[r.start:])
+		if !unicode.IsSpace(char) {
+			break
+		}
+
+		r.start += size
+	}
+
+	for r.end > r.start {
+		char, size := utf8.DecodeLastRuneInString(d.text[:r.end])
+		if !unicode.IsSpace(char) {
+			break
+		}
+
+		r.end -= size
+	}
+
+	return r
+}
+
+func (d *document) chunks(ranges []textRange) []Chunk {
+	chunks := make([]Chunk, 0, len(ranges))
+
+	for _, r := range ranges {
+		r = d.trim(r)
+		if r.start == r.end {
+			continue
+		}
+
+		chunks = append(chunks, Chunk{
+			// Form feeds are replaced by newlines of the same length, so the offsets stay valid
+			Text:              strings.ReplaceAll(d.text[r.start:r.end], "\f", "\n"),
+			Start:             r.start,
+			End:               r.end,
+			StartPage:         d.page(r.start),
+			EndPage:           d.page(r.end - 1),
+			StartMarginNumber: d.marginNumber(r.start),
+			EndMarginNumber:   d.marginNumber(r.end - 1),
+		})
+	}
+
+	return chunks
+}
@@ -0,0 +1,131 @@
+package chunking
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/JuliusMoehring/court-judgment-finder-crawler/pdf"
+)
+
+// Counts every word as a single token
+func countWords(text string) int {
+	return len(strings.Fields(text))
+}
+
+func Test_TokenChunker(t *testing.T) {
+	t.Run("Splits the text into chunks with overlap", func(t *testing.T) {
+		chunks := NewTokenChunker(countWords, 4, 2).Chunk([]pdf.NormalizedPage{
+			{Page: 1, Text: "eins zwei drei vier fünf sechs sieben"},
+		})
+
+		assert.Equal(t, []string{"eins zwei drei vier", "drei vier fünf sechs", "fünf sechs sieben"}, texts(chunks), "Should share two words between consecutive chunks")
+	})
+
+	t.Run("Always moves forward if the overlap is as large as the chunk", func(t *testing.T) {
+		chunks := NewTokenChunker(countWords, 2, 2).Chunk([]pdf.NormalizedPage{
+			{Page: 1, Text: "eins zwei drei"},
+		})
+
+		assert.Equal(t, []string{"eins zwei", "zwei drei"}, texts(chunks), "Should terminate")
+	})
+
+	t.Run("Stores offsets and pages", func(t *testing.T) {
+		pages := []pdf.NormalizedPage{
+			{Page: 1, Text: "eins zwei"},
+			{Page: 2, Text: "drei vier"},
+		}
+
+		chunks := NewTokenChunker(countWords, 3, 0).Chunk(pages)
+		text := pdf.NormalizedText(pages)
+
+		assert.Len(t, chunks, 2, "Should return two chunks")
+		assert.Equal(t, "eins zwei\ndrei", chunks[0].Text, "Should replace the form feed")
+		assert.Equal(t, 1, chunks[0].StartPage, "Should start on the first page")
+		assert.Equal(t, 2, chunks[0].EndPage, "Should end on the second page")
+		assert.Equal(t, "vier", text[chunks[1].Start:chunks[1].End], "Should return offsets in the normalized text")
+		assert.Equal(t, 2, chunks[1].StartPage, "Should start on the second page")
+	})
+
+	t.Run("Returns no chunks for empty documents", func(t *testing.T) {
+		chunks := NewTokenChunker(countWords, 4, 2).Chunk([]pdf.NormalizedPage{{Page: 1, Text: ""}})
+
+		assert.Empty(t, chunks, "Should return no chunks")
+	})
+}
+
+func Test_SentenceChunker(t *testing.T) {
+	t.Run("Groups whole sentences", func(t *testing.T) {
+		chunks := NewSentenceChunker(countWords, 8).Chunk([]pdf.NormalizedPage{
+			{Page: 1, Text: "Die Revision ist begründet. Das Urteil wird aufgehoben. Die Kosten trägt die Beklagte."},
+		})
+
+		assert.Equal(t, []string{"Die Revision ist begründet. Das Urteil wird aufgehoben.", "Die Kosten trägt die Beklagte."}, texts(chunks), "Should not split sentences")
+	})
+
+	t.Run("Does not split at abbreviations and dates", func(t *testing.T) {
+		doc := newDocument([]pdf.NormalizedPage{
+			{Page: 1, Text: "Nach § 5 Abs. 1 UWG, vgl. Urteil vom 12. März 2020, ist z.B. dies unlauter. Der I. Zivilsenat entscheidet."},
+		})
+
+		assert.Equal(t, []textRange{{start: 0, end: 78}, {start: 78, end: len(doc.text)}}, sentences(doc, textRange{start: 0, end: len(doc.text)}), "Should find two sentences")
+	})
+
+	t.Run("Splits at outline numbers", func(t *testing.T) {
+		doc := newDocument([]pdf.NormalizedPage{{Page: 1, Text: "Die Revision hat Erfolg. II.\nDie Klage ist zulässig."}})
+
+		assert.Len(t, sentences(doc, textRange{start: 0, end: len(doc.text)}), 3, "Should treat the outline number as a sentence")
+	})
+
+	t.Run("Splits sentences longer than the chunk size", func(t *testing.T) {
+		chunks := NewSentenceChunker(countWords, 2).Chunk([]pdf.NormalizedPage{
+			{Page: 1, Text: "Die Revision ist begründet."},
+		})
+
+		assert.Equal(t, []string{"Die Revision", "ist begründet."}, texts(chunks), "Should split the sentence")
+	})
+}
+
+func Test_MarginNumberChunker(t *testing.T) {
+	pages := pdf.Normalize([]string{
+		"BUNDESGERICHTSHOF\n\nURTEIL\n\n1\n\nDie Klägerin vertreibt Kaffee.\n\n2\n\nDie Beklagte",
+		"bewirbt Tee.\n\n3\n\nDie Revision hat Erfolg. Das Urteil wird aufgehoben.\n",
+	})
+
+	t.Run("Splits at Randnummern", func(t *testing.T) {
+		chunks := NewMarginNumberChunker(countWords, 100).Chunk(pages)
+
+		assert.Equal(t, []string{"BUNDESGERICHTSHOF\n\nURTEIL", "Die Klägerin vertreibt Kaffee.", "Die Beklagte\nbewirbt Tee.", "Die Revision hat Erfolg. Das Urteil wird aufgehoben."}, texts(chunks), "Should return the paragraphs")
+		assert.Equal(t, 0, chunks[0].StartMarginNumber, "Should not assign a Randnummer to the text in front of the first one")
+		assert.Equal(t, 2, chunks[2].StartMarginNumber, "Should assign the Randnummer")
+		assert.Equal(t, 1, chunks[2].StartPage, "Should start on the first page")
+		assert.Equal(t, 2, chunks[2].EndPage, "Should end on the second page")
+	})
+
+	t.Run("Splits long paragraphs into sentences", func(t *testing.T) {
+		chunks := NewMarginNumberChunker(countWords, 4).Chunk(pages)
+
+		assert.Equal(t, []string{"Die Revision hat Erfolg.", "Das Urteil wird aufgehoben."}, texts(chunks)[3:], "Should split the paragraph")
+		assert.Equal(t, 3, chunks[4].StartMarginNumber, "Should keep the Randnummer")
+		assert.Equal(t, 3, chunks[4].EndMarginNumber, "Should keep the Randnummer")
+	})
+
+	t.Run("Splits long paragraphs ending with a number", func(t *testing.T) {
+		pages := pdf.Normalize([]string{"1\n\nDie Klage ist nach § 5 Abs. 1 Nr. 2.\n\n2\n\nDie Revision hat Erfolg.\n"})
+
+		chunks := NewMarginNumberChunker(countWords, 3).Chunk(pages)
+
+		assert.Equal(t, []string{"Die Klage ist", "nach § 5", "Abs. 1 Nr.", "2.", "Die Revision hat", "Erfolg."}, texts(chunks), "Should not panic at the number at the end of the paragraph")
+	})
+}
+
+func texts(chunks []Chunk) []string {
+	texts := make([]string, len(chunks))
+
+	for i, chunk := range chunks {
+		texts[i] = chunk.Text
+	}
+
+	return texts
+}
@@ -0,0 +1,64 @@
+package chunking
+
+import (
+	"github.com/JuliusMoehring/court-judgment-finder-crawler/pdf"
+)
+
+const STRATEGY_MARGIN_NUMBERS = "margin-numbers"
+
+// Splits documents at their Randnummern, so every chunk is a paragraph of the judgement. Paragraphs longer than
+// size tokens are split into chunks of whole sentences, documents without Randnummern are chunked by sentences.
+type MarginNumberChunker struct {
+	counter TokenCounter
+	size    int
+}
+
+func NewMarginNumberChunker(counter TokenCounter, size int) Chunker {
+	return &MarginNumberChunker{
+		counter: counter,
+		size:    size,
+	}
+}
+
+func (c *MarginNumberChunker) Chunk(pages []pdf.NormalizedPage) []Chunk {
+	doc := newDocument(pages)
+
+	var ranges []textRange
+
+	for _, paragraph := range paragraphs(doc) {
+		if c.counter(doc.text[paragraph.start:paragraph.end]) <= c.size {
+			ranges = append(ranges, paragraph)
+			continue
+		}
+
+		ranges = append(ranges, sentenceRanges(doc, paragraph, c.counter, c.size)...)
+	}
+
+	return doc.chunks(ranges)
+}
+
+func (c *MarginNumberChunker) Strategy() string {
+	return STRATEGY_MARGIN_NUMBERS
+}
+
+// Returns the ranges between consecutive Randnummern, the text in front of the first Randnummer is a range of
+// its own
+func paragraphs(doc *document) []textRange {
+	var paragraphs []textRange
+
+	start := 0
+
+	for _, number := range doc.marginNumbers {
+		if number.offset > start {
+			paragraphs = append(paragraphs, textRange{start: start, end: number.offset})
+		}
+
+		start = number.offset
+	}
+
+	if start < len(doc.text) {
+		paragraphs = append(paragraphs, textRange{start: start, end: len(doc.text)})
+	}
+
+	return paragraphs
+}
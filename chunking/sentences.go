@@ -0,0 +1,168 @@
+package chunking
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/JuliusMoehring/court-judgment-finder-crawler/pdf"
+)
+
+const STRATEGY_SENTENCES = "sentences"
+
+// A sentence ends with a punctuation mark followed by whitespace, paragraphs always end a sentence
+var sentenceEndPattern = regexp.MustCompile(`[.!?:;]["“”)]*\s+|\n\n|\f`)
+
+// Abbreviations common in judgements that end with a period without ending the sentence
+var abbreviations = map[string]bool{
+	"a": true, "aao": true, "abs": true, "art": true, "aufl": true, "az": true, "b": true, "bd": true,
+	"beschl": true, "bgbl": true, "buchst": true, "bzw": true, "dr": true, "etc": true, "f": true, "ff": true,
+	"gem": true, "ggf": true, "hs": true, "i": true, "lit": true, "m": true, "mwn": true, "nr": true, "nrn": true,
+	"prof": true, "rdnr": true, "rn": true, "rspr": true, "s": true, "st": true, "str": true, "u": true,
+	"ua": true, "urt": true, "v": true, "vgl": true, "z": true, "ziff": true,
+}
+
+// Groups whole sentences into chunks of at most size tokens. Sentences longer than size are split into
+// chunks of size tokens.
+type SentenceChunker struct {
+	counter TokenCounter
+	size    int
+}
+
+func NewSentenceChunker(counter TokenCounter, size int) Chunker {
+	return &SentenceChunker{
+		counter: counter,
+		size:    size,
+	}
+}
+
+func (c *SentenceChunker) Chunk(pages []pdf.NormalizedPage) []Chunk {
+	doc := newDocument(pages)
+
+	return doc.chunks(sentenceRanges(doc, textRange{start: 0, end: len(doc.text)}, c.counter, c.size))
+}
+
+func (c *SentenceChunker) Strategy() string {
+	return STRATEGY_SENTENCES
+}
+
+// Returns the ranges of all sentences within the range
+func sentences(doc *document, r textRange) []textRange {
+	var sentences []textRange
+
+	start := r.start
+	text := doc.text[r.start:r.end]
+
+	for _, match := range sentenceEndPattern.FindAllStringIndex(text, -1) {
+		end := r.start + match[1]
+		sentence := doc.text[start : r.start+match[0]+1]
+
+		if isAbbreviation(sentence) || isOrdinal(sentence, text[match[0]:match[1]], doc.text[end:r.end]) {
+			continue
+		}
+
+		sentences = append(sentences, textRange{start: start, end: end})
+		start = end
+	}
+
+	if start < r.end {
+		sentences = append(sentences, textRange{start: start, end: r.end})
+	}
+
+	return sentences
+}
+
+// Returns whether the text ends with an abbreviation like "Abs." or "z.B."
+func isAbbreviation(text string) bool {
+	if !strings.HasSuffix(text, ".") {
+		return false
+	}
+
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return false
+	}
+
+	word := strings.TrimLeft(fields[len(fields)-1], "(\"„")
+	parts := strings.Split(strings.TrimSuffix(word, "."), ".")
+
+	return abbreviations[strings.ToLower(parts[len(parts)-1])]
+}
+
+// Returns whether the text ends with an ordinal like the "12." in "12. März 2020" or the "I." in "I. Zivilsenat"
+func isOrdinal(text string, separator string, next string) bool {
+	if !strings.HasSuffix(text, ".") {
+		return false
+	}
+
+	fields := strings.Fields(text)
+	if len(fields) == 0 {
+		return false
+	}
+
+	word := strings.TrimSuffix(fields[len(fields)-1], ".")
+	if word == "" {
+		return false
+	}
+
+	// Nothing follows the period at the end of the text
+	nextWord := strings.Fields(next)
+	if len(nextWord) == 0 {
+		return false
+	}
+
+	first, _ := utf8.DecodeRuneInString(next)
+
+	if strings.Trim(word, "0123456789") == "" {
+		return months[nextWord[0]] || unicode.IsLower(first)
+	}
+
+	// Roman numerals are outline numbers like "II." if the paragraph starts after them
+	if strings.Trim(word, "IVX") == "" {
+		return !strings.Contains(separator, "\n")
+	}
+
+	return false
+}
+
+var months = map[string]bool{
+	"Januar": true, "Februar": true, "März": true, "April": true, "Mai": true, "Juni": true, "Juli": true,
+	"August": true, "September": true, "Oktober": true, "November": true, "Dezember": true,
+}
+
+// Splits the range into chunks of whole sentences with at most size tokens
+func sentenceRanges(doc *document, r textRange, counter TokenCounter, size int) []textRange {
+	var ranges []textRange
+
+	current := textRange{start: -1}
+	tokens := 0
+
+	for _, sentence := range sentences(doc, r) {
+		sentenceTokens := counter(doc.text[sentence.start:sentence.end])
+
+		if current.start >= 0 && tokens+sentenceTokens > size {
+			ranges = append(ranges, current)
+			current = textRange{start: -1}
+			tokens = 0
+		}
+
+		if sentenceTokens > size {
+			ranges = append(ranges, tokenRanges(doc, sentence, counter, size, 0)...)
+			continue
+		}
+
+		if current.start < 0 {
+			current.start = sentence.start
+		}
+
+		current.end = sentence.end
+		tokens += sentenceTokens
+	}
+
+	if current.start >= 0 {
+		ranges = append(ranges, current)
+	}
+
+	return ranges
+}
@@ -0,0 +1,103 @@
+package chunking
+
+import (
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/JuliusMoehring/court-judgment-finder-crawler/pdf"
+)
+
+const STRATEGY_TOKENS = "tokens"
+
+// Splits documents into chunks of a fixed number of tokens, consecutive chunks share overlap tokens
+type TokenChunker struct {
+	counter TokenCounter
+	size    int
+	overlap int
+}
+
+func NewTokenChunker(counter TokenCounter, size int, overlap int) Chunker {
+	return &TokenChunker{
+		counter: counter,
+		size:    size,
+		overlap: overlap,
+	}
+}
+
+func (c *TokenChunker) Chunk(pages []pdf.NormalizedPage) []Chunk {
+	doc := newDocument(pages)
+
+	return doc.chunks(tokenRanges(doc, textRange{start: 0, end: len(doc.text)}, c.counter, c.size, c.overlap))
+}
+
+func (c *TokenChunker) Strategy() string {
+	return STRATEGY_TOKENS
+}
+
+// Returns the ranges of all words within the range
+func words(doc *document, r textRange) []textRange {
+	var words []textRange
+
+	start := -1
+
+	for offset := r.start; offset < r.end; {
+		char, size := utf8.DecodeRuneInString(doc.text[offset:])
+
+		if unicode.IsSpace(char) && start >= 0 {
+			words = append(words, textRange{start: start, end: offset})
+			start = -1
+		} else if !unicode.IsSpace(char) && start < 0 {
+			start = offset
+		}
+
+		offset += size
+	}
+
+	if start >= 0 {
+		words = append(words, textRange{start: start, end: r.end})
+	}
+
+	return words
+}
+
+// Splits the range at word boundaries into ranges of at most size tokens. A single word longer than size
+// becomes a range of its own.
+func tokenRanges(doc *document, r textRange, counter TokenCounter, size int, overlap int) []textRange {
+	words := words(doc, r)
+	tokens := make([]int, len(words))
+
+	for i, word := range words {
+		tokens[i] = counter(doc.text[word.start:word.end])
+	}
+
+	var ranges []textRange
+
+	for start := 0; start < len(words); {
+		end := start + 1
+		total := tokens[start]
+
+		for end < len(words) && total+tokens[end] <= size {
+			total += tokens[end]
+			end++
+		}
+
+		ranges = append(ranges, textRange{start: words[start].start, end: words[end-1].end})
+
+		if end == len(words) {
+			break
+		}
+
+		// Start the next chunk with the last words of this chunk, but always move forward
+		next := end
+		shared := 0
+
+		for next-1 > start && shared+tokens[next-1] <= overlap {
+			next--
+			shared += tokens[next]
+		}
+
+		start = next
+	}
+
+	return ranges
+}
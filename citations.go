@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/JuliusMoehring/court-judgment-finder-crawler/logger"
+	vectorstore "github.com/JuliusMoehring/court-judgment-finder-crawler/vector-store"
+)
+
+// Prints the decisions a document cites, or with -cited-by the documents citing it
+func runCitations(ctx context.Context, logger logger.Logger, args []string) error {
+	flags := flag.NewFlagSet("citations", flag.ExitOnError)
+
+	citedBy := flags.Bool("cited-by", false, "list the documents citing the document instead of the decisions it cites")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if flags.NArg() != 1 {
+		return fmt.Errorf("usage: citations [-cited-by] <file path>")
+	}
+
+	path := flags.Arg(0)
+
+	vectorStore := vectorstore.NewPostgresVectorStore(ctx, logger)
+	defer vectorStore.Close()
+
+	if *citedBy {
+		citations, err := vectorStore.ListCitedBy(ctx, path)
+		if err != nil {
+			return err
+		}
+
+		for _, citation := range citations {
+			fmt.Printf("%s (page %d): %s\n", citation.FilePath, citation.Page, citation.Text)
+		}
+
+		return nil
+	}
+
+	citations, err := vectorStore.ListCitations(ctx, path)
+	if err != nil {
+		return err
+	}
+
+	for _, citation := range citations {
+		target := citation.CitedFilePath
+		if target == "" {
+			target = "not stored"
+		}
+
+		fmt.Printf("page %d: %s -> %s\n", citation.Page, citation.Text, target)
+	}
+
+	return nil
+}
@@ -0,0 +1,60 @@
+package embedder
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+const DEFAULT_AZURE_OPENAI_API_VERSION = "2024-02-01"
+
+// Options of an Azure OpenAI deployment. The model is the model of the deployment, it decides the supported
+// dimensions and is stored with the embeddings. BaseURL and OrganizationID do not apply to Azure.
+type AzureOpenAIEmbedderOptions struct {
+	OpenAIEmbedderOptions
+
+	// Endpoint of the resource, e.g. "https://my-resource.openai.azure.com"
+	Endpoint string
+	// Name of the deployment of the model, defaults to the name of the model without dots
+	Deployment string
+	APIVersion string
+	// Sends the API key as Microsoft Entra ID token instead of an api-key header
+	EntraID bool
+}
+
+func NewAzureOpenAIEmbedder(options AzureOpenAIEmbedderOptions) (Embedder, error) {
+	if options.Endpoint == "" {
+		return nil, fmt.Errorf("%w: azure endpoint", ErrMissingOption)
+	}
+
+	if options.APIKey == "" {
+		return nil, fmt.Errorf("%w: azure api key", ErrMissingOption)
+	}
+
+	if options.APIVersion == "" {
+		options.APIVersion = DEFAULT_AZURE_OPENAI_API_VERSION
+	}
+
+	config := openai.DefaultAzureConfig(options.APIKey, strings.TrimSuffix(options.Endpoint, "/"))
+	config.APIVersion = options.APIVersion
+
+	if options.EntraID {
+		config.APIType = openai.APITypeAzureAD
+	}
+
+	if options.Deployment != "" {
+		config.AzureModelMapperFunc = func(model string) string {
+			return options.Deployment
+		}
+	}
+
+	httpClient, err := newHTTPClient(options.ProxyURL)
+	if err != nil {
+		return nil, err
+	}
+
+	config.HTTPClient = httpClient
+
+	return newOpenAIEmbedder(openai.NewClientWithConfig(config), options.OpenAIEmbedderOptions)
+}
@@ -0,0 +1,121 @@
+package embedder
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+)
+
+// Returns a server answering every request with embeddings of the dimensions after passing the request to handle
+func newEmbeddingServer(t *testing.T, dimensions int, handle func(r *http.Request)) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handle(r)
+
+		var request struct {
+			Input []string `json:"input"`
+		}
+
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		response := openai.EmbeddingResponse{Object: "list"}
+
+		for i := range request.Input {
+			response.Data = append(response.Data, openai.Embedding{Object: "embedding", Index: i, Embedding: make([]float32, dimensions)})
+		}
+
+		json.NewEncoder(w).Encode(response)
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func Test_AzureOpenAIEmbedder(t *testing.T) {
+	t.Run("Sends requests to the deployment", func(t *testing.T) {
+		server := newEmbeddingServer(t, 256, func(r *http.Request) {
+			assert.Equal(t, "/openai/deployments/embeddings-prod/embeddings", r.URL.Path, "Should request the deployment")
+			assert.Equal(t, "2024-06-01", r.URL.Query().Get("api-version"), "Should send the api version")
+			assert.Equal(t, "secret", r.Header.Get("api-key"), "Should send the api key")
+		})
+
+		embedder, err := NewAzureOpenAIEmbedder(AzureOpenAIEmbedderOptions{
+			OpenAIEmbedderOptions: OpenAIEmbedderOptions{APIKey: "secret", Model: "text-embedding-3-small", Dimensions: 256},
+			Endpoint:              server.URL,
+			Deployment:            "embeddings-prod",
+			APIVersion:            "2024-06-01",
+		})
+		assert.NoError(t, err, "Should create the embedder")
+
+		embedding, err := embedder.Embed(context.Background(), "Urteil")
+		assert.NoError(t, err, "Should embed the text")
+		assert.Len(t, embedding, 256, "Should return the dimensions")
+		assert.Equal(t, "text-embedding-3-small", embedder.Model(), "Should return the model, not the deployment")
+	})
+
+	t.Run("Sends Entra ID tokens as bearer tokens", func(t *testing.T) {
+		server := newEmbeddingServer(t, 1536, func(r *http.Request) {
+			assert.Equal(t, "Bearer token", r.Header.Get("Authorization"), "Should send the token")
+			assert.Equal(t, DEFAULT_AZURE_OPENAI_API_VERSION, r.URL.Query().Get("api-version"), "Should default the api version")
+		})
+
+		embedder, err := NewAzureOpenAIEmbedder(AzureOpenAIEmbedderOptions{
+			OpenAIEmbedderOptions: OpenAIEmbedderOptions{APIKey: "token"},
+			Endpoint:              server.URL,
+			EntraID:               true,
+		})
+		assert.NoError(t, err, "Should create the embedder")
+
+		_, err = embedder.Embed(context.Background(), "Urteil")
+		assert.NoError(t, err, "Should embed the text")
+	})
+
+	t.Run("Requires an endpoint and a key", func(t *testing.T) {
+		_, err := NewAzureOpenAIEmbedder(AzureOpenAIEmbedderOptions{OpenAIEmbedderOptions: OpenAIEmbedderOptions{APIKey: "secret"}})
+		assert.ErrorIs(t, err, ErrMissingOption, "Should require the endpoint")
+
+		_, err = NewAzureOpenAIEmbedder(AzureOpenAIEmbedderOptions{Endpoint: "https://example.openai.azure.com"})
+		assert.ErrorIs(t, err, ErrMissingOption, "Should require the key")
+	})
+}
+
+func Test_OpenAIEmbedderConnection(t *testing.T) {
+	t.Run("Sends requests to the base url with the organization", func(t *testing.T) {
+		server := newEmbeddingServer(t, 1536, func(r *http.Request) {
+			assert.Equal(t, "/gateway/v1/embeddings", r.URL.Path, "Should request the base url")
+			assert.Equal(t, "org-123", r.Header.Get("OpenAI-Organization"), "Should send the organization")
+			assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"), "Should send the key")
+		})
+
+		embedder, err := NewOpenAIEmbedder(OpenAIEmbedderOptions{APIKey: "secret", BaseURL: server.URL + "/gateway/v1/", OrganizationID: "org-123"})
+		assert.NoError(t, err, "Should create the embedder")
+
+		_, err = embedder.Embed(context.Background(), "Urteil")
+		assert.NoError(t, err, "Should embed the text")
+	})
+
+	t.Run("Sends requests through the proxy", func(t *testing.T) {
+		proxy := newEmbeddingServer(t, 1536, func(r *http.Request) {
+			assert.Equal(t, "api.example.invalid", r.URL.Host, "Should forward the request for the api to the proxy")
+		})
+
+		embedder, err := NewOpenAIEmbedder(OpenAIEmbedderOptions{APIKey: "secret", BaseURL: "http://api.example.invalid/v1", ProxyURL: proxy.URL})
+		assert.NoError(t, err, "Should create the embedder")
+
+		_, err = embedder.Embed(context.Background(), "Urteil")
+		assert.NoError(t, err, "Should embed the text")
+	})
+
+	t.Run("Rejects invalid proxy urls", func(t *testing.T) {
+		_, err := NewOpenAIEmbedder(OpenAIEmbedderOptions{APIKey: "secret", ProxyURL: "proxy"})
+
+		assert.Error(t, err, "Should reject the url")
+	})
+}
@@ -0,0 +1,103 @@
+package embedder
+
+import (
+	"context"
+	"sync"
+	"unicode/utf8"
+)
+
+// Roughly four characters make up a token for most embedding models
+const ESTIMATED_CHARACTERS_PER_TOKEN = 4
+
+// Estimates the number of tokens without a tokenizer
+func EstimateTokens(text string) int {
+	return (utf8.RuneCountInString(text) + ESTIMATED_CHARACTERS_PER_TOKEN - 1) / ESTIMATED_CHARACTERS_PER_TOKEN
+}
+
+type batch struct {
+	start int
+	end   int
+}
+
+// Splits the texts into consecutive batches of at most maxInputs texts and maxTokens tokens. A text with more
+// than maxTokens tokens becomes a batch of its own, zero limits mean no limit.
+func batches(texts []string, counter func(text string) int, maxInputs int, maxTokens int) []batch {
+	var batches []batch
+
+	current := batch{}
+	tokens := 0
+
+	for i, text := range texts {
+		textTokens := counter(text)
+
+		full := maxInputs > 0 && current.end-current.start >= maxInputs
+		tooLarge := maxTokens > 0 && tokens+textTokens > maxTokens
+
+		if current.end > current.start && (full || tooLarge) {
+			batches = append(batches, current)
+			current = batch{start: i, end: i}
+			tokens = 0
+		}
+
+		current.end = i + 1
+		tokens += textTokens
+	}
+
+	if current.end > current.start {
+		batches = append(batches, current)
+	}
+
+	return batches
+}
+
+// Embeds the batches concurrently and returns the embeddings in the order of the texts. The number of
+// concurrent requests is limited by the semaphore, which is shared by all callers of an embedder.
+func embedBatches(ctx context.Context, texts []string, batches []batch, semaphore chan struct{}, embed func(ctx context.Context, texts []string) ([][]float32, error)) ([][]float32, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	embeddings := make([][]float32, len(texts))
+
+	var wg sync.WaitGroup
+	var once sync.Once
+	var firstErr error
+
+	fail := func(err error) {
+		once.Do(func() {
+			firstErr = err
+			cancel()
+		})
+	}
+
+	for _, b := range batches {
+		wg.Add(1)
+
+		go func(b batch) {
+			defer wg.Done()
+
+			select {
+			case semaphore <- struct{}{}:
+				defer func() { <-semaphore }()
+			case <-ctx.Done():
+				fail(ctx.Err())
+				return
+			}
+
+			result, err := embed(ctx, texts[b.start:b.end])
+			if err != nil {
+				fail(err)
+				return
+			}
+
+			copy(embeddings[b.start:b.end], result)
+		}(b)
+	}
+
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	return embeddings, nil
+}
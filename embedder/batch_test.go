@@ -0,0 +1,49 @@
+package embedder
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Counts every text as its length in tokens
+func countLength(text string) int {
+	return len(text)
+}
+
+func Test_batches(t *testing.T) {
+	t.Run("Limits the number of inputs", func(t *testing.T) {
+		assert.Equal(t, []batch{{0, 2}, {2, 4}, {4, 5}}, batches([]string{"a", "b", "c", "d", "e"}, countLength, 2, 0), "Should split into batches of two")
+	})
+
+	t.Run("Limits the number of tokens", func(t *testing.T) {
+		assert.Equal(t, []batch{{0, 2}, {2, 3}, {3, 4}}, batches([]string{"aa", "bb", "cccc", "d"}, countLength, 0, 4), "Should split when the tokens exceed the limit")
+	})
+
+	t.Run("Puts texts larger than the limit into their own batch", func(t *testing.T) {
+		assert.Equal(t, []batch{{0, 1}, {1, 2}, {2, 3}}, batches([]string{"a", "bbbbbb", "c"}, countLength, 0, 4), "Should not merge the large text")
+	})
+
+	t.Run("Returns no batches for no texts", func(t *testing.T) {
+		assert.Empty(t, batches(nil, countLength, 2, 4), "Should return no batches")
+	})
+}
+
+func Test_embedBatches(t *testing.T) {
+	t.Run("Returns the first error", func(t *testing.T) {
+		errFailed := errors.New("failed")
+
+		_, err := embedBatches(context.Background(), []string{"a", "b"}, []batch{{0, 1}, {1, 2}}, make(chan struct{}, 1), func(ctx context.Context, texts []string) ([][]float32, error) {
+			return nil, errFailed
+		})
+
+		assert.ErrorIs(t, err, errFailed, "Should return the error of the request")
+	})
+}
+
+func Test_EstimateTokens(t *testing.T) {
+	assert.Equal(t, 0, EstimateTokens(""), "Should return zero for empty text")
+	assert.Equal(t, 1, EstimateTokens("Über"), "Should count characters instead of bytes")
+}
@@ -0,0 +1,107 @@
+package embedder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+var ErrBudgetExceeded = errors.New("embedding budget exceeded")
+
+type BudgetReport struct {
+	// Tokens sent to the embedder
+	SpentTokens int
+	LimitTokens int
+	// Texts that were not embedded because they did not fit into the budget anymore
+	RejectedTexts  int
+	RejectedTokens int
+}
+
+// Stops embedding once the texts would exceed the budget. Batches are either embedded completely or rejected
+// with ErrBudgetExceeded, failed batches do not count against the budget.
+type BudgetedEmbedder struct {
+	embedder    Embedder
+	counter     func(text string) int
+	limitTokens int
+
+	mutex  sync.Mutex
+	report BudgetReport
+}
+
+func NewBudgetedEmbedder(embedder Embedder, counter func(text string) int, limitTokens int) *BudgetedEmbedder {
+	return &BudgetedEmbedder{
+		embedder:    embedder,
+		counter:     counter,
+		limitTokens: limitTokens,
+		report:      BudgetReport{LimitTokens: limitTokens},
+	}
+}
+
+func (e *BudgetedEmbedder) Embed(ctx context.Context, text string) ([]float32, error) {
+	embeddings, err := e.EmbedBatch(ctx, []string{text})
+	if err != nil {
+		return nil, err
+	}
+
+	return embeddings[0], nil
+}
+
+func (e *BudgetedEmbedder) EmbedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	tokens := 0
+
+	for _, text := range texts {
+		tokens += e.counter(text)
+	}
+
+	if !e.reserve(len(texts), tokens) {
+		return nil, fmt.Errorf("%w: %d tokens needed, %d of %d tokens left", ErrBudgetExceeded, tokens, e.limitTokens-e.Report().SpentTokens, e.limitTokens)
+	}
+
+	embeddings, err := e.embedder.EmbedBatch(ctx, texts)
+	if err != nil {
+		e.refund(tokens)
+		return nil, err
+	}
+
+	return embeddings, nil
+}
+
+func (e *BudgetedEmbedder) Model() string {
+	return e.embedder.Model()
+}
+
+func (e *BudgetedEmbedder) Dimensions() int {
+	return e.embedder.Dimensions()
+}
+
+// Returns the spent and the rejected tokens since the embedder was created
+func (e *BudgetedEmbedder) Report() BudgetReport {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	return e.report
+}
+
+// Counts the tokens against the budget if they fit, otherwise counts the texts as rejected
+func (e *BudgetedEmbedder) reserve(texts int, tokens int) bool {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if e.report.SpentTokens+tokens > e.limitTokens {
+		e.report.RejectedTexts += texts
+		e.report.RejectedTokens += tokens
+		return false
+	}
+
+	e.report.SpentTokens += tokens
+
+	return true
+}
+
+func (e *BudgetedEmbedder) refund(tokens int) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	e.report.SpentTokens -= tokens
+}
@@ -0,0 +1,52 @@
+package embedder
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Counts every word as a single token
+func countWords(text string) int {
+	return len(strings.Fields(text))
+}
+
+// Fails every request
+type failingEmbedder struct {
+	countingEmbedder
+}
+
+func (e *failingEmbedder) EmbedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	return nil, errors.New("unavailable")
+}
+
+func Test_BudgetedEmbedder(t *testing.T) {
+	t.Run("Embeds texts until the budget is spent", func(t *testing.T) {
+		inner := &countingEmbedder{}
+		budgeted := NewBudgetedEmbedder(inner, countWords, 5)
+
+		_, err := budgeted.EmbedBatch(context.Background(), []string{"eins zwei", "drei"})
+		assert.NoError(t, err, "Should embed texts within the budget")
+
+		_, err = budgeted.EmbedBatch(context.Background(), []string{"vier fünf sechs"})
+		assert.ErrorIs(t, err, ErrBudgetExceeded, "Should reject texts exceeding the budget")
+
+		_, err = budgeted.Embed(context.Background(), "vier fünf")
+		assert.NoError(t, err, "Should embed texts fitting into the rest of the budget")
+
+		assert.Equal(t, []string{"eins zwei", "drei", "vier fünf"}, inner.texts, "Should not send rejected texts")
+		assert.Equal(t, BudgetReport{SpentTokens: 5, LimitTokens: 5, RejectedTexts: 1, RejectedTokens: 3}, budgeted.Report(), "Should report spent and rejected tokens")
+	})
+
+	t.Run("Does not count failed requests", func(t *testing.T) {
+		budgeted := NewBudgetedEmbedder(&failingEmbedder{}, countWords, 5)
+
+		_, err := budgeted.EmbedBatch(context.Background(), []string{"eins zwei"})
+
+		assert.Error(t, err, "Should return the error")
+		assert.Equal(t, 0, budgeted.Report().SpentTokens, "Should refund the tokens")
+	})
+}
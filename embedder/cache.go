@@ -0,0 +1,172 @@
+package embedder
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/JuliusMoehring/court-judgment-finder-crawler/logger"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Identifies an embedding independent of insignificant differences in the whitespace of the text
+type CacheKey struct {
+	Model      string
+	Dimensions int
+	// Handling of texts exceeding the context window, see ContextWindowEmbedder.Policy. Empty if the texts are
+	// embedded as they are.
+	Policy string
+	// Hex encoded SHA-256 hash of the normalized text
+	TextHash string
+}
+
+// Entries matching any of the conditions are evicted, zero values are ignored
+type EvictParams struct {
+	// Evicts entries that were not used since the given time
+	UnusedSince time.Time
+	// Evicts the entries of all other models and dimensions
+	KeepModel      string
+	KeepDimensions int
+}
+
+type Cache interface {
+	// Returns the cached embeddings of the keys, keys without an entry are missing from the result
+	Get(ctx context.Context, keys []CacheKey) (map[CacheKey][]float32, error)
+	Set(ctx context.Context, entries map[CacheKey][]float32) error
+	// Returns the number of evicted entries
+	Evict(ctx context.Context, params EvictParams) (int, error)
+}
+
+// Returns the hash of the text in NFC with collapsed whitespace
+func TextHash(text string) string {
+	hash := sha256.Sum256([]byte(strings.Join(strings.Fields(norm.NFC.String(text)), " ")))
+
+	return hex.EncodeToString(hash[:])
+}
+
+type CacheStats struct {
+	Hits   int64
+	Misses int64
+}
+
+// Embeds only texts without a cached embedding of the same model, dimensions and policy. Failures of the cache
+// are logged and the texts are embedded as if they were not cached.
+type CachedEmbedder struct {
+	logger   logger.Logger
+	embedder Embedder
+	cache    Cache
+	policy   string
+
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+// The policy is part of every cache key, it must change whenever the embedder embeds the same text differently
+func NewCachedEmbedder(logger logger.Logger, embedder Embedder, cache Cache, policy string) *CachedEmbedder {
+	return &CachedEmbedder{
+		logger:   logger,
+		embedder: embedder,
+		cache:    cache,
+		policy:   policy,
+	}
+}
+
+func (e *CachedEmbedder) Model() string {
+	return e.embedder.Model()
+}
+
+func (e *CachedEmbedder) Dimensions() int {
+	return e.embedder.Dimensions()
+}
+
+// Returns the number of cache hits and misses since the embedder was created
+func (e *CachedEmbedder) Stats() CacheStats {
+	return CacheStats{
+		Hits:   e.hits.Load(),
+		Misses: e.misses.Load(),
+	}
+}
+
+func (e *CachedEmbedder) Embed(ctx context.Context, text string) ([]float32, error) {
+	embeddings, err := e.EmbedBatch(ctx, []string{text})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(embeddings) == 0 {
+		return nil, NoEmbeddingsReturnedError
+	}
+
+	return embeddings[0], nil
+}
+
+func (e *CachedEmbedder) EmbedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	keys := make([]CacheKey, len(texts))
+
+	for i, text := range texts {
+		keys[i] = CacheKey{
+			Model:      e.embedder.Model(),
+			Dimensions: e.embedder.Dimensions(),
+			Policy:     e.policy,
+			TextHash:   TextHash(text),
+		}
+	}
+
+	cached, err := e.cache.Get(ctx, keys)
+	if err != nil {
+		e.logger.Warnf("embedder", "failed reading embedding cache: %s", err)
+		cached = map[CacheKey][]float32{}
+	}
+
+	embeddings := make([][]float32, len(texts))
+
+	// Identical texts within the batch are only embedded once
+	missing := map[CacheKey][]int{}
+	var missingTexts []string
+	var missingKeys []CacheKey
+
+	for i, key := range keys {
+		if embedding, ok := cached[key]; ok {
+			embeddings[i] = embedding
+			continue
+		}
+
+		if _, ok := missing[key]; !ok {
+			missingTexts = append(missingTexts, texts[i])
+			missingKeys = append(missingKeys, key)
+		}
+
+		missing[key] = append(missing[key], i)
+	}
+
+	e.hits.Add(int64(len(texts) - len(missingTexts)))
+	e.misses.Add(int64(len(missingTexts)))
+
+	if len(missingTexts) == 0 {
+		return embeddings, nil
+	}
+
+	created, err := e.embedder.EmbedBatch(ctx, missingTexts)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make(map[CacheKey][]float32, len(created))
+
+	for i, embedding := range created {
+		entries[missingKeys[i]] = embedding
+
+		for _, index := range missing[missingKeys[i]] {
+			embeddings[index] = embedding
+		}
+	}
+
+	if err := e.cache.Set(ctx, entries); err != nil {
+		e.logger.Warnf("embedder", "failed writing embedding cache: %s", err)
+	}
+
+	return embeddings, nil
+}
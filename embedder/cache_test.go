@@ -0,0 +1,153 @@
+package embedder
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	filestorage "github.com/JuliusMoehring/court-judgment-finder-crawler/file-storage"
+	"github.com/JuliusMoehring/court-judgment-finder-crawler/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+// Embeds every text as its length and records the embedded texts
+type countingEmbedder struct {
+	texts []string
+}
+
+func (e *countingEmbedder) Model() string {
+	return "counting"
+}
+
+func (e *countingEmbedder) Dimensions() int {
+	return 1
+}
+
+func (e *countingEmbedder) Embed(ctx context.Context, text string) ([]float32, error) {
+	embeddings, err := e.EmbedBatch(ctx, []string{text})
+	if err != nil {
+		return nil, err
+	}
+
+	return embeddings[0], nil
+}
+
+func (e *countingEmbedder) EmbedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	e.texts = append(e.texts, texts...)
+
+	embeddings := make([][]float32, len(texts))
+
+	for i, text := range texts {
+		embeddings[i] = []float32{float32(len(text))}
+	}
+
+	return embeddings, nil
+}
+
+// Fails every operation
+type failingCache struct{}
+
+func (c failingCache) Get(ctx context.Context, keys []CacheKey) (map[CacheKey][]float32, error) {
+	return nil, errors.New("unavailable")
+}
+
+func (c failingCache) Set(ctx context.Context, entries map[CacheKey][]float32) error {
+	return errors.New("unavailable")
+}
+
+func (c failingCache) Evict(ctx context.Context, params EvictParams) (int, error) {
+	return 0, errors.New("unavailable")
+}
+
+func Test_CachedEmbedder(t *testing.T) {
+	t.Run("Only embeds texts that are not cached", func(t *testing.T) {
+		inner := &countingEmbedder{}
+		embedder := NewCachedEmbedder(logger.NewStdOutLogger(), inner, NewFileCache(filestorage.NewLocalFileStorage(t.TempDir())), "")
+
+		embeddings, err := embedder.EmbedBatch(context.Background(), []string{"a", "bb", "a"})
+		assert.NoError(t, err, "Should not return an error")
+		assert.Equal(t, [][]float32{{1}, {2}, {1}}, embeddings, "Should return all embeddings")
+		assert.Equal(t, []string{"a", "bb"}, inner.texts, "Should embed duplicates once")
+
+		embeddings, err = embedder.EmbedBatch(context.Background(), []string{"bb", " a\n", "ccc"})
+		assert.NoError(t, err, "Should not return an error")
+		assert.Equal(t, [][]float32{{2}, {1}, {3}}, embeddings, "Should return cached and new embeddings")
+		assert.Equal(t, []string{"a", "bb", "ccc"}, inner.texts, "Should only embed the new text")
+		assert.Equal(t, CacheStats{Hits: 3, Misses: 3}, embedder.Stats(), "Should count hits and misses")
+	})
+
+	t.Run("Embeds texts again with another policy", func(t *testing.T) {
+		cache := NewFileCache(filestorage.NewLocalFileStorage(t.TempDir()))
+		inner := &countingEmbedder{}
+
+		_, err := NewCachedEmbedder(logger.NewStdOutLogger(), inner, cache, "split-8191").Embed(context.Background(), "a")
+		assert.NoError(t, err, "Should not return an error")
+
+		truncating := NewCachedEmbedder(logger.NewStdOutLogger(), inner, cache, "truncate-8191")
+
+		_, err = truncating.Embed(context.Background(), "a")
+		assert.NoError(t, err, "Should not return an error")
+		assert.Equal(t, []string{"a", "a"}, inner.texts, "Should not use the embedding of the other policy")
+		assert.Equal(t, CacheStats{Hits: 0, Misses: 1}, truncating.Stats(), "Should count a miss")
+	})
+
+	t.Run("Embeds texts if the cache fails", func(t *testing.T) {
+		inner := &countingEmbedder{}
+		embedder := NewCachedEmbedder(logger.NewStdOutLogger(), inner, failingCache{}, "")
+
+		embedding, err := embedder.Embed(context.Background(), "a")
+		assert.NoError(t, err, "Should not return an error")
+		assert.Equal(t, []float32{1}, embedding, "Should return the embedding")
+	})
+}
+
+func Test_FileCache(t *testing.T) {
+	ctx := context.Background()
+
+	ada := CacheKey{Model: "text-embedding-ada-002", Dimensions: 2, TextHash: TextHash("Urteil")}
+	ollama := CacheKey{Model: "nomic-embed-text:latest", Dimensions: 2, TextHash: TextHash("Urteil")}
+
+	t.Run("Returns stored embeddings per model", func(t *testing.T) {
+		cache := NewFileCache(filestorage.NewLocalFileStorage(t.TempDir()))
+
+		assert.NoError(t, cache.Set(ctx, map[CacheKey][]float32{ada: {1, 2}}), "Should not return an error")
+
+		embeddings, err := cache.Get(ctx, []CacheKey{ada, ollama})
+		assert.NoError(t, err, "Should not return an error")
+		assert.Equal(t, map[CacheKey][]float32{ada: {1, 2}}, embeddings, "Should only return the embedding of the model")
+	})
+
+	t.Run("Evicts other models", func(t *testing.T) {
+		cache := NewFileCache(filestorage.NewLocalFileStorage(t.TempDir()))
+
+		assert.NoError(t, cache.Set(ctx, map[CacheKey][]float32{ada: {1, 2}, ollama: {3, 4}}), "Should not return an error")
+
+		evicted, err := cache.Evict(ctx, EvictParams{KeepModel: ollama.Model, KeepDimensions: 2})
+		assert.NoError(t, err, "Should not return an error")
+		assert.Equal(t, 1, evicted, "Should evict one entry")
+
+		embeddings, _ := cache.Get(ctx, []CacheKey{ada, ollama})
+		assert.Equal(t, map[CacheKey][]float32{ollama: {3, 4}}, embeddings, "Should keep the entry of the model")
+	})
+
+	t.Run("Evicts unused entries", func(t *testing.T) {
+		cache := NewFileCache(filestorage.NewLocalFileStorage(t.TempDir()))
+
+		assert.NoError(t, cache.Set(ctx, map[CacheKey][]float32{ada: {1, 2}}), "Should not return an error")
+
+		evicted, err := cache.Evict(ctx, EvictParams{UnusedSince: time.Now().Add(-time.Hour)})
+		assert.NoError(t, err, "Should not return an error")
+		assert.Equal(t, 0, evicted, "Should keep recently used entries")
+
+		evicted, err = cache.Evict(ctx, EvictParams{UnusedSince: time.Now().Add(time.Hour)})
+		assert.NoError(t, err, "Should not return an error")
+		assert.Equal(t, 1, evicted, "Should evict entries unused since")
+	})
+}
+
+func Test_TextHash(t *testing.T) {
+	assert.Equal(t, TextHash("Das Urteil"), TextHash(" Das\n Urteil "), "Should ignore whitespace")
+	assert.Equal(t, TextHash("Kl\u00e4ger"), TextHash("Kla\u0308ger"), "Should normalize unicode")
+	assert.NotEqual(t, TextHash("Kläger"), TextHash("Klager"), "Should distinguish texts")
+}
@@ -0,0 +1,257 @@
+package embedder
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/JuliusMoehring/court-judgment-finder-crawler/logger"
+)
+
+const (
+	// Overlong texts are split into parts that fit into the context window, the embeddings of the parts are
+	// averaged weighted by their tokens
+	OVERLONG_POLICY_SPLIT = "split"
+	// Overlong texts are embedded without the tokens exceeding the context window
+	OVERLONG_POLICY_TRUNCATE = "truncate"
+
+	// Context window of all OpenAI embedding models
+	OPENAI_MAX_INPUT_TOKENS = 8191
+)
+
+// Words including the whitespace in front of them
+var wordPattern = regexp.MustCompile(`\s*\S+`)
+
+// A text that exceeded the context window
+type OverlongInput struct {
+	TextHash string
+	Tokens   int
+	// Number of embedded parts, 1 if the text was truncated
+	Parts int
+}
+
+// Handles texts with more than maxTokens tokens according to the policy instead of sending them to the
+// embedder, which would reject them
+type ContextWindowEmbedder struct {
+	logger    logger.Logger
+	embedder  Embedder
+	counter   func(text string) int
+	maxTokens int
+	policy    string
+
+	mutex    sync.Mutex
+	overlong []OverlongInput
+}
+
+func NewContextWindowEmbedder(logger logger.Logger, embedder Embedder, counter func(text string) int, maxTokens int, policy string) *ContextWindowEmbedder {
+	return &ContextWindowEmbedder{
+		logger:    logger,
+		embedder:  embedder,
+		counter:   counter,
+		maxTokens: maxTokens,
+		policy:    policy,
+	}
+}
+
+func (e *ContextWindowEmbedder) Model() string {
+	return e.embedder.Model()
+}
+
+func (e *ContextWindowEmbedder) Dimensions() int {
+	return e.embedder.Dimensions()
+}
+
+// Identifies how overlong texts are embedded, e.g. "split-8191", to keep cached embeddings of different
+// policies apart
+func (e *ContextWindowEmbedder) Policy() string {
+	return fmt.Sprintf("%s-%d", e.policy, e.maxTokens)
+}
+
+// Returns the texts that exceeded the context window since the embedder was created
+func (e *ContextWindowEmbedder) Overlong() []OverlongInput {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	return append([]OverlongInput{}, e.overlong...)
+}
+
+func (e *ContextWindowEmbedder) Embed(ctx context.Context, text string) ([]float32, error) {
+	embeddings, err := e.EmbedBatch(ctx, []string{text})
+	if err != nil {
+		return nil, err
+	}
+
+	return embeddings[0], nil
+}
+
+func (e *ContextWindowEmbedder) EmbedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	var parts []string
+	var weights []int
+	// Index of the first part of every text, the parts of a text are consecutive
+	starts := make([]int, len(texts)+1)
+
+	for i, text := range texts {
+		starts[i] = len(parts)
+
+		tokens := e.counter(text)
+		if tokens <= e.maxTokens {
+			parts = append(parts, text)
+			weights = append(weights, tokens)
+			continue
+		}
+
+		textParts := splitTokens(text, e.counter, e.maxTokens)
+		if e.policy == OVERLONG_POLICY_TRUNCATE {
+			textParts = textParts[:1]
+		}
+
+		for _, part := range textParts {
+			parts = append(parts, part)
+			weights = append(weights, e.counter(part))
+		}
+
+		e.report(i, len(texts), text, tokens, len(textParts))
+	}
+
+	starts[len(texts)] = len(parts)
+
+	embeddings, err := e.embedder.EmbedBatch(ctx, parts)
+	if err != nil {
+		return nil, err
+	}
+
+	pooled := make([][]float32, len(texts))
+
+	for i := range texts {
+		start, end := starts[i], starts[i+1]
+
+		if end-start == 1 {
+			pooled[i] = embeddings[start]
+			continue
+		}
+
+		pooled[i] = pool(embeddings[start:end], weights[start:end])
+	}
+
+	return pooled, nil
+}
+
+func (e *ContextWindowEmbedder) report(index int, count int, text string, tokens int, parts int) {
+	input := OverlongInput{TextHash: TextHash(text), Tokens: tokens, Parts: parts}
+
+	e.mutex.Lock()
+	e.overlong = append(e.overlong, input)
+	e.mutex.Unlock()
+
+	if e.policy == OVERLONG_POLICY_TRUNCATE {
+		e.logger.Warnf("embedder", "input %d of %d with %d tokens exceeds the context window of %d tokens, truncated: %s", index+1, count, tokens, e.maxTokens, input.TextHash)
+		return
+	}
+
+	e.logger.Warnf("embedder", "input %d of %d with %d tokens exceeds the context window of %d tokens, split into %d parts: %s", index+1, count, tokens, e.maxTokens, parts, input.TextHash)
+}
+
+// Splits the text at whitespace into parts of at most maxTokens tokens, words longer than maxTokens are split
+// between runes
+func splitTokens(text string, counter func(text string) int, maxTokens int) []string {
+	var parts []string
+
+	start, end, tokens := 0, 0, 0
+
+	flush := func() {
+		if part := strings.TrimSpace(text[start:end]); part != "" {
+			parts = append(parts, part)
+		}
+
+		start, tokens = end, 0
+	}
+
+	for _, word := range wordPattern.FindAllStringIndex(text, -1) {
+		wordTokens := counter(text[word[0]:word[1]])
+
+		if wordTokens > maxTokens {
+			flush()
+			parts = append(parts, splitRunes(strings.TrimSpace(text[word[0]:word[1]]), counter, maxTokens)...)
+			start, end = word[1], word[1]
+			continue
+		}
+
+		if tokens+wordTokens > maxTokens {
+			flush()
+		}
+
+		end = word[1]
+		tokens += wordTokens
+	}
+
+	flush()
+
+	// Only whitespace
+	if len(parts) == 0 {
+		return splitRunes(text, counter, maxTokens)
+	}
+
+	return parts
+}
+
+// Splits the word into the longest prefixes of at most maxTokens tokens, every part has at least one rune
+func splitRunes(word string, counter func(text string) int, maxTokens int) []string {
+	var parts []string
+
+	for word != "" {
+		runes := []rune(word)
+
+		// Largest number of runes that fit
+		low, high := 1, len(runes)
+		for low < high {
+			middle := (low + high + 1) / 2
+
+			if counter(string(runes[:middle])) <= maxTokens {
+				low = middle
+			} else {
+				high = middle - 1
+			}
+		}
+
+		part := string(runes[:low])
+		parts = append(parts, part)
+		word = word[len(part):]
+	}
+
+	return parts
+}
+
+// Averages the embeddings weighted by the tokens of their texts and normalizes the result to unit length
+func pool(embeddings [][]float32, weights []int) []float32 {
+	sums := make([]float64, len(embeddings[0]))
+
+	for i, embedding := range embeddings {
+		weight := float64(max(weights[i], 1))
+
+		for j, value := range embedding {
+			sums[j] += float64(value) * weight
+		}
+	}
+
+	norm := 0.0
+	for _, sum := range sums {
+		norm += sum * sum
+	}
+
+	norm = math.Sqrt(norm)
+
+	pooled := make([]float32, len(sums))
+
+	for j, sum := range sums {
+		if norm > 0 {
+			sum /= norm
+		}
+
+		pooled[j] = float32(sum)
+	}
+
+	return pooled
+}
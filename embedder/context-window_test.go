@@ -0,0 +1,53 @@
+package embedder
+
+import (
+	"context"
+	"testing"
+
+	"github.com/JuliusMoehring/court-judgment-finder-crawler/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ContextWindowEmbedder(t *testing.T) {
+	t.Run("Splits overlong texts and pools their embeddings", func(t *testing.T) {
+		inner := &countingEmbedder{}
+		embedder := NewContextWindowEmbedder(logger.NewStdOutLogger(), inner, countWords, 2, OVERLONG_POLICY_SPLIT)
+
+		embeddings, err := embedder.EmbedBatch(context.Background(), []string{"eins", "zwei drei vier"})
+
+		assert.NoError(t, err, "Should embed the texts")
+		assert.Equal(t, []string{"eins", "zwei drei", "vier"}, inner.texts, "Should embed the parts")
+		assert.Equal(t, [][]float32{{4}, {1}}, embeddings, "Should return one normalized embedding per text")
+		assert.Equal(t, []OverlongInput{{TextHash: TextHash("zwei drei vier"), Tokens: 3, Parts: 2}}, embedder.Overlong(), "Should report the overlong text")
+	})
+
+	t.Run("Truncates overlong texts", func(t *testing.T) {
+		inner := &countingEmbedder{}
+		embedder := NewContextWindowEmbedder(logger.NewStdOutLogger(), inner, countWords, 2, OVERLONG_POLICY_TRUNCATE)
+
+		embedding, err := embedder.Embed(context.Background(), "zwei drei vier")
+
+		assert.NoError(t, err, "Should embed the text")
+		assert.Equal(t, []string{"zwei drei"}, inner.texts, "Should only embed the first part")
+		assert.Equal(t, []float32{9}, embedding, "Should return the embedding of the first part")
+		assert.Equal(t, 1, embedder.Overlong()[0].Parts, "Should report a single part")
+	})
+}
+
+func Test_splitTokens(t *testing.T) {
+	t.Run("Splits at whitespace", func(t *testing.T) {
+		assert.Equal(t, []string{"eins zwei", "drei vier", "fünf"}, splitTokens("eins zwei\ndrei vier  fünf ", countWords, 2), "Should split into parts of two words")
+	})
+
+	t.Run("Splits words longer than the limit", func(t *testing.T) {
+		assert.Equal(t, []string{"ab", "abcd", "efg"}, splitTokens("ab abcdefg", countLength, 4), "Should split the word between runes")
+	})
+}
+
+func Test_pool(t *testing.T) {
+	t.Run("Averages weighted by tokens and normalizes", func(t *testing.T) {
+		pooled := pool([][]float32{{1, 0}, {0, 1}}, []int{3, 4})
+
+		assert.InDeltaSlice(t, []float32{0.6, 0.8}, pooled, 1e-6, "Should weight the embeddings")
+	})
+}
@@ -4,4 +4,11 @@ import "context"
 
 type Embedder interface {
 	Embed(ctx context.Context, text string) ([]float32, error)
+	// Returns the embeddings of all texts in the same order, texts are sent in as few requests as the
+	// limits of the provider allow
+	EmbedBatch(ctx context.Context, texts []string) ([][]float32, error)
+	// Name of the model the embeddings are created with, stored with every embedding
+	Model() string
+	// Number of dimensions of every returned embedding
+	Dimensions() int
 }
@@ -0,0 +1,181 @@
+package embedder
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	filestorage "github.com/JuliusMoehring/court-judgment-finder-crawler/file-storage"
+)
+
+const FILE_CACHE_PREFIX = "embedding-cache/"
+
+type fileCacheEntry struct {
+	Embedding []float32 `json:"embedding"`
+	// File storages do not record when a file was read, entries are rewritten when they are used
+	UsedAt time.Time `json:"usedAt"`
+}
+
+// Stores every embedding as a file at embedding-cache/<model>/<dimensions>/<text hash>.json, or at
+// embedding-cache/<model>/<dimensions>/<policy>/<text hash>.json if the key has a policy
+type FileCache struct {
+	storage filestorage.FileStorage
+	// Entries are only rewritten once per interval to record their use
+	touchInterval time.Duration
+}
+
+func NewFileCache(storage filestorage.FileStorage) Cache {
+	return &FileCache{
+		storage:       storage,
+		touchInterval: 24 * time.Hour,
+	}
+}
+
+func (c *FileCache) path(key CacheKey) string {
+	if key.Policy != "" {
+		return fmt.Sprintf("%s%s/%d/%s/%s.json", FILE_CACHE_PREFIX, url.PathEscape(key.Model), key.Dimensions, url.PathEscape(key.Policy), key.TextHash)
+	}
+
+	return fmt.Sprintf("%s%s/%d/%s.json", FILE_CACHE_PREFIX, url.PathEscape(key.Model), key.Dimensions, key.TextHash)
+}
+
+func (c *FileCache) read(ctx context.Context, path string) (*fileCacheEntry, error) {
+	data, err := c.storage.Read(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+
+	var entry fileCacheEntry
+
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, fmt.Errorf("invalid cache entry '%s': %w", path, err)
+	}
+
+	return &entry, nil
+}
+
+func (c *FileCache) write(ctx context.Context, path string, entry fileCacheEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	return c.storage.Save(ctx, data, path)
+}
+
+func (c *FileCache) Get(ctx context.Context, keys []CacheKey) (map[CacheKey][]float32, error) {
+	embeddings := map[CacheKey][]float32{}
+
+	for _, key := range keys {
+		if _, ok := embeddings[key]; ok {
+			continue
+		}
+
+		path := c.path(key)
+
+		entry, err := c.read(ctx, path)
+		if errors.Is(err, filestorage.ErrFileNotFound) {
+			continue
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		if len(entry.Embedding) != key.Dimensions {
+			continue
+		}
+
+		if time.Since(entry.UsedAt) > c.touchInterval {
+			entry.UsedAt = time.Now().UTC()
+
+			if err := c.write(ctx, path, *entry); err != nil {
+				return nil, err
+			}
+		}
+
+		embeddings[key] = entry.Embedding
+	}
+
+	return embeddings, nil
+}
+
+func (c *FileCache) Set(ctx context.Context, entries map[CacheKey][]float32) error {
+	for key, embedding := range entries {
+		if err := c.write(ctx, c.path(key), fileCacheEntry{Embedding: embedding, UsedAt: time.Now().UTC()}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (c *FileCache) Evict(ctx context.Context, params EvictParams) (int, error) {
+	paths, err := c.storage.List(ctx, FILE_CACHE_PREFIX)
+	if err != nil {
+		return 0, err
+	}
+
+	evicted := 0
+
+	for _, path := range paths {
+		evict, err := c.shouldEvict(ctx, path, params)
+		if err != nil {
+			return evicted, err
+		}
+
+		if !evict {
+			continue
+		}
+
+		if err := c.storage.Delete(ctx, path); err != nil {
+			return evicted, err
+		}
+
+		evicted++
+	}
+
+	return evicted, nil
+}
+
+func (c *FileCache) shouldEvict(ctx context.Context, path string, params EvictParams) (bool, error) {
+	if params.KeepModel != "" || params.KeepDimensions > 0 {
+		// <model>/<dimensions>/<text hash>.json or <model>/<dimensions>/<policy>/<text hash>.json
+		parts := strings.Split(strings.TrimPrefix(path, FILE_CACHE_PREFIX), "/")
+		if len(parts) != 3 && len(parts) != 4 {
+			return false, nil
+		}
+
+		model, err := url.PathUnescape(parts[0])
+		if err != nil {
+			return false, nil
+		}
+
+		dimensions, _ := strconv.Atoi(parts[1])
+
+		if (params.KeepModel != "" && model != params.KeepModel) || (params.KeepDimensions > 0 && dimensions != params.KeepDimensions) {
+			return true, nil
+		}
+	}
+
+	if params.UnusedSince.IsZero() {
+		return false, nil
+	}
+
+	entry, err := c.read(ctx, path)
+	if errors.Is(err, filestorage.ErrFileNotFound) {
+		return false, nil
+	}
+
+	// Unreadable entries are evicted as well
+	if err != nil {
+		return true, nil
+	}
+
+	return entry.UsedAt.Before(params.UnusedSince), nil
+}
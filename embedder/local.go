@@ -0,0 +1,38 @@
+package embedder
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrMissingOption = errors.New("missing embedder option")
+
+const DEFAULT_OLLAMA_BASE_URL = "http://localhost:11434"
+
+// Options of embedders running on a local server, the model and its dimensions cannot be looked up
+type LocalEmbedderOptions struct {
+	// e.g. "http://localhost:11434" for Ollama or "http://localhost:8080/v1" for OpenAI-compatible servers
+	BaseURL    string
+	Model      string
+	Dimensions int
+	// Only sent if set, most local servers do not require a key
+	APIKey         string
+	MaxBatchInputs int
+	MaxConcurrency int
+}
+
+func (o LocalEmbedderOptions) validate() error {
+	if o.BaseURL == "" {
+		return fmt.Errorf("%w: base url", ErrMissingOption)
+	}
+
+	if o.Model == "" {
+		return fmt.Errorf("%w: model", ErrMissingOption)
+	}
+
+	if o.Dimensions <= 0 {
+		return fmt.Errorf("%w: dimensions of %s", ErrMissingOption, o.Model)
+	}
+
+	return nil
+}
@@ -0,0 +1,126 @@
+package embedder
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Ollama embeds a single prompt per request
+type OllamaEmbedder struct {
+	options   LocalEmbedderOptions
+	semaphore chan struct{}
+
+	client *http.Client
+}
+
+// Creates an embedder for the /api/embeddings endpoint of Ollama, the base URL defaults to the local server
+func NewOllamaEmbedder(options LocalEmbedderOptions) (Embedder, error) {
+	if options.BaseURL == "" {
+		options.BaseURL = DEFAULT_OLLAMA_BASE_URL
+	}
+
+	if err := options.validate(); err != nil {
+		return nil, err
+	}
+
+	if options.MaxConcurrency <= 0 {
+		options.MaxConcurrency = DEFAULT_EMBEDDING_CONCURRENCY
+	}
+
+	return &OllamaEmbedder{
+		options:   options,
+		semaphore: make(chan struct{}, options.MaxConcurrency),
+		client:    http.DefaultClient,
+	}, nil
+}
+
+func (e *OllamaEmbedder) Model() string {
+	return e.options.Model
+}
+
+func (e *OllamaEmbedder) Dimensions() int {
+	return e.options.Dimensions
+}
+
+func (e *OllamaEmbedder) Embed(ctx context.Context, text string) ([]float32, error) {
+	embeddings, err := e.EmbedBatch(ctx, []string{text})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(embeddings) == 0 {
+		return nil, NoEmbeddingsReturnedError
+	}
+
+	return embeddings[0], nil
+}
+
+func (e *OllamaEmbedder) EmbedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	if len(texts) == 0 {
+		return [][]float32{}, nil
+	}
+
+	return embedBatches(ctx, texts, batches(texts, EstimateTokens, 1, 0), e.semaphore, func(ctx context.Context, texts []string) ([][]float32, error) {
+		embedding, err := e.embed(ctx, texts[0])
+		if err != nil {
+			return nil, err
+		}
+
+		return [][]float32{embedding}, nil
+	})
+}
+
+func (e *OllamaEmbedder) embed(ctx context.Context, text string) ([]float32, error) {
+	body, err := json.Marshal(map[string]string{
+		"model":  e.options.Model,
+		"prompt": text,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(e.options.BaseURL, "/")+"/api/embeddings", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+
+	if e.options.APIKey != "" {
+		request.Header.Set("Authorization", "Bearer "+e.options.APIKey)
+	}
+
+	response, err := e.client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(response.Body, 1024))
+		return nil, fmt.Errorf("ollama returned status %d: %s", response.StatusCode, strings.TrimSpace(string(message)))
+	}
+
+	var result struct {
+		Embedding []float32 `json:"embedding"`
+	}
+
+	if err := json.NewDecoder(response.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+
+	if len(result.Embedding) == 0 {
+		return nil, NoEmbeddingsReturnedError
+	}
+
+	if len(result.Embedding) != e.options.Dimensions {
+		return nil, fmt.Errorf("embedding with %d dimensions returned, expected %d", len(result.Embedding), e.options.Dimensions)
+	}
+
+	return result.Embedding, nil
+}
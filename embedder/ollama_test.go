@@ -0,0 +1,88 @@
+package embedder
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Returns a stand-in for the Ollama API that embeds every prompt as its length followed by zeros
+func newTestOllamaServer(t *testing.T, dimensions int) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/embeddings" || r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		var request struct {
+			Model  string `json:"model"`
+			Prompt string `json:"prompt"`
+		}
+
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		if request.Model != "nomic-embed-text" {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":"model not found"}`))
+			return
+		}
+
+		embedding := make([]float32, dimensions)
+		embedding[0] = float32(len(request.Prompt))
+
+		json.NewEncoder(w).Encode(map[string]interface{}{"embedding": embedding})
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func Test_OllamaEmbedder(t *testing.T) {
+	t.Run("Embeds every text", func(t *testing.T) {
+		server := newTestOllamaServer(t, 768)
+
+		embedder, err := NewOllamaEmbedder(LocalEmbedderOptions{BaseURL: server.URL, Model: "nomic-embed-text", Dimensions: 768})
+		assert.NoError(t, err, "Should not return an error")
+
+		embeddings, err := embedder.EmbedBatch(context.Background(), []string{"a", "bb", "ccc"})
+		assert.NoError(t, err, "Should not return an error")
+		assert.Equal(t, []float32{1, 2, 3}, firstDimensions(embeddings), "Should return the embeddings in order")
+		assert.Len(t, embeddings[0], 768, "Should return the configured dimensions")
+		assert.Equal(t, "nomic-embed-text", embedder.Model(), "Should return the model")
+	})
+
+	t.Run("Returns errors of the server", func(t *testing.T) {
+		server := newTestOllamaServer(t, 768)
+
+		embedder, err := NewOllamaEmbedder(LocalEmbedderOptions{BaseURL: server.URL, Model: "unknown", Dimensions: 768})
+		assert.NoError(t, err, "Should not return an error")
+
+		_, err = embedder.Embed(context.Background(), "Urteil")
+		assert.ErrorContains(t, err, "model not found", "Should return the message of the server")
+	})
+
+	t.Run("Rejects embeddings with other dimensions", func(t *testing.T) {
+		server := newTestOllamaServer(t, 384)
+
+		embedder, err := NewOllamaEmbedder(LocalEmbedderOptions{BaseURL: server.URL, Model: "nomic-embed-text", Dimensions: 768})
+		assert.NoError(t, err, "Should not return an error")
+
+		_, err = embedder.Embed(context.Background(), "Urteil")
+		assert.Error(t, err, "Should return an error")
+	})
+
+	t.Run("Requires model and dimensions", func(t *testing.T) {
+		_, err := NewOllamaEmbedder(LocalEmbedderOptions{Model: "nomic-embed-text"})
+		assert.ErrorIs(t, err, ErrMissingOption, "Should require the dimensions")
+
+		_, err = NewOllamaEmbedder(LocalEmbedderOptions{Dimensions: 768})
+		assert.ErrorIs(t, err, ErrMissingOption, "Should require the model")
+	})
+}
@@ -0,0 +1,23 @@
+package embedder
+
+import (
+	"github.com/sashabaranov/go-openai"
+)
+
+// Creates an embedder for servers implementing the embeddings endpoint of the OpenAI API, e.g. vLLM, LocalAI or
+// llama.cpp. The base URL includes the version path, e.g. "http://localhost:8080/v1".
+func NewOpenAICompatibleEmbedder(options LocalEmbedderOptions) (Embedder, error) {
+	if err := options.validate(); err != nil {
+		return nil, err
+	}
+
+	config := openai.DefaultConfig(options.APIKey)
+	config.BaseURL = options.BaseURL
+
+	return newOpenAIEmbedderWithClient(openai.NewClientWithConfig(config), OpenAIEmbedderOptions{
+		Model:          options.Model,
+		Dimensions:     options.Dimensions,
+		MaxBatchInputs: options.MaxBatchInputs,
+		MaxConcurrency: options.MaxConcurrency,
+	}, false), nil
+}
@@ -0,0 +1,59 @@
+package embedder
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_OpenAICompatibleEmbedder(t *testing.T) {
+	t.Run("Sends batches to the configured base url", func(t *testing.T) {
+		var authorization string
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/v1/embeddings" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+
+			authorization = r.Header.Get("Authorization")
+
+			var request struct {
+				Input      []string `json:"input"`
+				Model      string   `json:"model"`
+				Dimensions int      `json:"dimensions"`
+			}
+
+			json.NewDecoder(r.Body).Decode(&request)
+
+			assert.Equal(t, "bge-m3", request.Model, "Should request the configured model")
+			assert.Zero(t, request.Dimensions, "Should not send the dimensions")
+
+			data := []map[string]interface{}{}
+
+			for i, input := range request.Input {
+				data = append(data, map[string]interface{}{"object": "embedding", "index": i, "embedding": []float32{float32(len(input)), 0, 0}})
+			}
+
+			json.NewEncoder(w).Encode(map[string]interface{}{"object": "list", "data": data})
+		}))
+		t.Cleanup(server.Close)
+
+		embedder, err := NewOpenAICompatibleEmbedder(LocalEmbedderOptions{BaseURL: server.URL + "/v1", Model: "bge-m3", Dimensions: 3, APIKey: "secret"})
+		assert.NoError(t, err, "Should not return an error")
+
+		embeddings, err := embedder.EmbedBatch(context.Background(), []string{"a", "bb"})
+		assert.NoError(t, err, "Should not return an error")
+		assert.Equal(t, [][]float32{{1, 0, 0}, {2, 0, 0}}, embeddings, "Should return the embeddings")
+		assert.Equal(t, "Bearer secret", authorization, "Should send the api key")
+	})
+
+	t.Run("Requires a base url", func(t *testing.T) {
+		_, err := NewOpenAICompatibleEmbedder(LocalEmbedderOptions{Model: "bge-m3", Dimensions: 1024})
+		assert.ErrorIs(t, err, ErrMissingOption, "Should require the base url")
+	})
+}
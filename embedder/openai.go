@@ -3,33 +3,211 @@ package embedder
 import (
 	"context"
 	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
 	"os"
-	"sync"
+	"strings"
 
 	"github.com/sashabaranov/go-openai"
 )
 
-var NoEmbeddingsReturnedError = errors.New("no embeddings returned")
+var (
+	NoEmbeddingsReturnedError = errors.New("no embeddings returned")
+	ErrUnsupportedModel       = errors.New("unsupported embedding model")
+	ErrUnsupportedDimensions  = errors.New("unsupported embedding dimensions")
+)
+
+const (
+	// OpenAI accepts at most 2048 inputs per request
+	OPENAI_MAX_BATCH_INPUTS = 2048
+	// OpenAI accepts at most 300000 tokens per request, estimated tokens need some headroom
+	OPENAI_MAX_BATCH_TOKENS = 200000
+	// Number of requests in flight at the same time across all workers
+	DEFAULT_EMBEDDING_CONCURRENCY = 4
+	DEFAULT_OPENAI_MODEL          = string(openai.AdaEmbeddingV2)
+)
+
+type openAIModel struct {
+	dimensions int
+	// Whether the model can return embeddings with fewer dimensions
+	reducible bool
+	// Price in US dollars
+	pricePerMillionTokens float64
+}
+
+var openAIModels = map[string]openAIModel{
+	string(openai.AdaEmbeddingV2):  {dimensions: 1536, pricePerMillionTokens: 0.10},
+	string(openai.SmallEmbedding3): {dimensions: 1536, reducible: true, pricePerMillionTokens: 0.02},
+	string(openai.LargeEmbedding3): {dimensions: 3072, reducible: true, pricePerMillionTokens: 0.13},
+}
+
+// Returns the price of the OpenAI model in US dollars per million tokens, false for unknown models
+func OpenAIPricePerMillionTokens(model string) (float64, bool) {
+	if model == "" {
+		model = DEFAULT_OPENAI_MODEL
+	}
+
+	m, ok := openAIModels[model]
+
+	return m.pricePerMillionTokens, ok
+}
+
+// Zero values are replaced by the defaults
+type OpenAIEmbedderOptions struct {
+	// Defaults to OPENAI_API_KEY
+	APIKey string
+	// Replaces https://api.openai.com/v1, e.g. to send requests through a gateway
+	BaseURL string
+	// Sent with every request if set, for keys with access to several organizations
+	OrganizationID string
+	// URL of the HTTP proxy for all requests, defaults to the proxy of the environment (HTTPS_PROXY)
+	ProxyURL string
+
+	Model string
+	// Defaults to the dimensions of the model, text-embedding-3 models support fewer dimensions
+	Dimensions     int
+	MaxBatchInputs int
+	MaxBatchTokens int
+	MaxConcurrency int
+	// Counts the tokens of a text, defaults to EstimateTokens
+	TokenCounter func(text string) int
+}
 
 type OpenAIEmbedder struct {
-	mu sync.Mutex
+	options   OpenAIEmbedderOptions
+	semaphore chan struct{}
+	// Whether the dimensions are sent with every request
+	sendDimensions bool
 
 	client *openai.Client
 }
 
-func NewOpenAIEmbedder() Embedder {
+func NewOpenAIEmbedder(options OpenAIEmbedderOptions) (Embedder, error) {
+	if options.APIKey == "" {
+		options.APIKey = os.Getenv("OPENAI_API_KEY")
+	}
+
+	config := openai.DefaultConfig(options.APIKey)
+	config.OrgID = options.OrganizationID
+
+	if options.BaseURL != "" {
+		config.BaseURL = strings.TrimSuffix(options.BaseURL, "/")
+	}
+
+	httpClient, err := newHTTPClient(options.ProxyURL)
+	if err != nil {
+		return nil, err
+	}
+
+	config.HTTPClient = httpClient
+
+	return newOpenAIEmbedder(openai.NewClientWithConfig(config), options)
+}
+
+// Returns a client sending requests through the proxy, or through the proxy of the environment if it is empty
+func newHTTPClient(proxyURL string) (*http.Client, error) {
+	if proxyURL == "" {
+		return &http.Client{}, nil
+	}
+
+	proxy, err := url.Parse(proxyURL)
+	if err != nil || proxy.Host == "" {
+		return nil, fmt.Errorf("invalid proxy url: '%s'", proxyURL)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyURL(proxy)
+
+	return &http.Client{Transport: transport}, nil
+}
+
+func newOpenAIEmbedder(client *openai.Client, options OpenAIEmbedderOptions) (*OpenAIEmbedder, error) {
+	if options.Model == "" {
+		options.Model = DEFAULT_OPENAI_MODEL
+	}
+
+	model, ok := openAIModels[options.Model]
+	if !ok {
+		return nil, fmt.Errorf("%w: '%s'", ErrUnsupportedModel, options.Model)
+	}
+
+	if options.Dimensions == 0 {
+		options.Dimensions = model.dimensions
+	}
+
+	if options.Dimensions < 0 || options.Dimensions > model.dimensions || (options.Dimensions != model.dimensions && !model.reducible) {
+		return nil, fmt.Errorf("%w: %s supports %d dimensions, got %d", ErrUnsupportedDimensions, options.Model, model.dimensions, options.Dimensions)
+	}
+
+	return newOpenAIEmbedderWithClient(client, options, model.reducible), nil
+}
+
+// Applies the default limits without validating the model
+func newOpenAIEmbedderWithClient(client *openai.Client, options OpenAIEmbedderOptions, sendDimensions bool) *OpenAIEmbedder {
+	if options.MaxBatchInputs <= 0 {
+		options.MaxBatchInputs = OPENAI_MAX_BATCH_INPUTS
+	}
+
+	if options.MaxBatchTokens <= 0 {
+		options.MaxBatchTokens = OPENAI_MAX_BATCH_TOKENS
+	}
+
+	if options.MaxConcurrency <= 0 {
+		options.MaxConcurrency = DEFAULT_EMBEDDING_CONCURRENCY
+	}
+
+	if options.TokenCounter == nil {
+		options.TokenCounter = EstimateTokens
+	}
+
 	return &OpenAIEmbedder{
-		client: openai.NewClient(os.Getenv("OPENAI_API_KEY")),
+		options:        options,
+		semaphore:      make(chan struct{}, options.MaxConcurrency),
+		sendDimensions: sendDimensions,
+		client:         client,
 	}
 }
 
 func (e *OpenAIEmbedder) Embed(ctx context.Context, text string) ([]float32, error) {
-	e.mu.Lock()
-	defer e.mu.Unlock()
+	embeddings, err := e.EmbedBatch(ctx, []string{text})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(embeddings) == 0 {
+		return nil, NoEmbeddingsReturnedError
+	}
+
+	return embeddings[0], nil
+}
+
+func (e *OpenAIEmbedder) Model() string {
+	return e.options.Model
+}
 
+func (e *OpenAIEmbedder) Dimensions() int {
+	return e.options.Dimensions
+}
+
+func (e *OpenAIEmbedder) EmbedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	if len(texts) == 0 {
+		return [][]float32{}, nil
+	}
+
+	return embedBatches(ctx, texts, batches(texts, e.options.TokenCounter, e.options.MaxBatchInputs, e.options.MaxBatchTokens), e.semaphore, e.embed)
+}
+
+// Sends a single request for all texts
+func (e *OpenAIEmbedder) embed(ctx context.Context, texts []string) ([][]float32, error) {
 	request := openai.EmbeddingRequest{
-		Input: []string{text},
-		Model: openai.AdaEmbeddingV2,
+		Input: texts,
+		Model: openai.EmbeddingModel(e.options.Model),
+	}
+
+	// Only text-embedding-3 models accept the parameter
+	if e.sendDimensions {
+		request.Dimensions = e.options.Dimensions
 	}
 
 	response, err := e.client.CreateEmbeddings(ctx, request)
@@ -37,9 +215,25 @@ func (e *OpenAIEmbedder) Embed(ctx context.Context, text string) ([]float32, err
 		return nil, err
 	}
 
-	if len(response.Data) == 0 {
-		return nil, NoEmbeddingsReturnedError
+	embeddings := make([][]float32, len(texts))
+
+	for _, data := range response.Data {
+		if data.Index < 0 || data.Index >= len(texts) {
+			return nil, fmt.Errorf("embedding for unknown input %d returned", data.Index)
+		}
+
+		if len(data.Embedding) != e.options.Dimensions {
+			return nil, fmt.Errorf("embedding with %d dimensions returned, expected %d", len(data.Embedding), e.options.Dimensions)
+		}
+
+		embeddings[data.Index] = data.Embedding
+	}
+
+	for i, embedding := range embeddings {
+		if embedding == nil {
+			return nil, fmt.Errorf("input %d of %d: %w", i+1, len(texts), NoEmbeddingsReturnedError)
+		}
 	}
 
-	return response.Data[0].Embedding, nil
+	return embeddings, nil
 }
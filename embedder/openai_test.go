@@ -2,23 +2,198 @@ package embedder
 
 import (
 	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
 	"testing"
+	"time"
 
-	"github.com/joho/godotenv"
+	"github.com/sashabaranov/go-openai"
 	"github.com/stretchr/testify/assert"
 )
 
 func Test_OpenAIEmbedder(t *testing.T) {
-	t.Run("", func(t *testing.T) {
-		err := godotenv.Load("../.env")
-		if err != nil {
-			t.Logf("error loading .env file: %v", err)
-		}
-
-		embedder := NewOpenAIEmbedder()
+	t.Run("Embeds the text", func(t *testing.T) {
+		embedder := newTestOpenAIEmbedder(t, OpenAIEmbedderOptions{}, func(request openai.EmbeddingRequest) {
+			assert.Equal(t, []string{"Hello, my dog is cute"}, request.Input, "Should send the text")
+		})
 
 		embedding, err := embedder.Embed(context.Background(), "Hello, my dog is cute")
 		assert.NoError(t, err, "Should not return an error")
 		assert.Len(t, embedding, 1536, "Should return the correct number of embeddings")
 	})
 }
+
+// Calls the real API, only runs if OPENAI_API_KEY is set in the environment
+func Test_OpenAIEmbedderLive(t *testing.T) {
+	if os.Getenv("OPENAI_API_KEY") == "" {
+		t.Skip("OPENAI_API_KEY is not set")
+	}
+
+	embedder, err := NewOpenAIEmbedder(OpenAIEmbedderOptions{})
+	assert.NoError(t, err, "Should not return an error")
+
+	embedding, err := embedder.Embed(context.Background(), "Hello, my dog is cute")
+	assert.NoError(t, err, "Should not return an error")
+	assert.Len(t, embedding, 1536, "Should return the correct number of embeddings")
+}
+
+// Returns an embedder against a stand-in for the OpenAI API. The first dimension of every embedding is the length
+// of the input.
+func newTestOpenAIEmbedder(t *testing.T, options OpenAIEmbedderOptions, handle func(request openai.EmbeddingRequest)) *OpenAIEmbedder {
+	var embedder *OpenAIEmbedder
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			Input      []string `json:"input"`
+			Model      string   `json:"model"`
+			Dimensions int      `json:"dimensions"`
+		}
+
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		handle(openai.EmbeddingRequest{Input: request.Input, Model: openai.EmbeddingModel(request.Model), Dimensions: request.Dimensions})
+
+		response := openai.EmbeddingResponse{Object: "list"}
+
+		// Reversed on purpose, the index decides the order
+		for i := len(request.Input) - 1; i >= 0; i-- {
+			embedding := make([]float32, embedder.Dimensions())
+			embedding[0] = float32(len(request.Input[i]))
+
+			response.Data = append(response.Data, openai.Embedding{
+				Object:    "embedding",
+				Index:     i,
+				Embedding: embedding,
+			})
+		}
+
+		json.NewEncoder(w).Encode(response)
+	}))
+	t.Cleanup(server.Close)
+
+	config := openai.DefaultConfig("test")
+	config.BaseURL = server.URL + "/v1"
+
+	embedder, err := newOpenAIEmbedder(openai.NewClientWithConfig(config), options)
+	if err != nil {
+		t.Fatalf("failed creating embedder: %s", err)
+	}
+
+	return embedder
+}
+
+func firstDimensions(embeddings [][]float32) []float32 {
+	first := make([]float32, len(embeddings))
+
+	for i, embedding := range embeddings {
+		first[i] = embedding[0]
+	}
+
+	return first
+}
+
+func Test_OpenAIEmbedderBatch(t *testing.T) {
+	t.Run("Returns the embeddings in the order of the texts", func(t *testing.T) {
+		var mu sync.Mutex
+		requests := 0
+
+		embedder := newTestOpenAIEmbedder(t, OpenAIEmbedderOptions{MaxBatchInputs: 2}, func(request openai.EmbeddingRequest) {
+			mu.Lock()
+			defer mu.Unlock()
+			requests++
+		})
+
+		embeddings, err := embedder.EmbedBatch(context.Background(), []string{"a", "bb", "ccc", "dddd", "eeeee"})
+		assert.NoError(t, err, "Should not return an error")
+		assert.Equal(t, []float32{1, 2, 3, 4, 5}, firstDimensions(embeddings), "Should return the embeddings in order")
+		assert.Equal(t, 3, requests, "Should send three requests")
+	})
+
+	t.Run("Sends requests concurrently", func(t *testing.T) {
+		var mu sync.Mutex
+		running, maxRunning := 0, 0
+
+		// Closed once three requests are in flight, every request waits for it
+		inFlight := make(chan struct{})
+		var closeInFlight sync.Once
+
+		embedder := newTestOpenAIEmbedder(t, OpenAIEmbedderOptions{MaxBatchInputs: 1, MaxConcurrency: 3}, func(request openai.EmbeddingRequest) {
+			mu.Lock()
+			running++
+			maxRunning = max(maxRunning, running)
+
+			if running == 3 {
+				closeInFlight.Do(func() { close(inFlight) })
+			}
+			mu.Unlock()
+
+			select {
+			case <-inFlight:
+			// Only guards against hanging forever if the requests are sent one after another
+			case <-time.After(10 * time.Second):
+				t.Error("Should send three requests at the same time")
+			}
+
+			mu.Lock()
+			running--
+			mu.Unlock()
+		})
+
+		_, err := embedder.EmbedBatch(context.Background(), []string{"a", "b", "c", "d", "e", "f"})
+		assert.NoError(t, err, "Should not return an error")
+		assert.LessOrEqual(t, maxRunning, 3, "Should not run more requests than allowed at the same time")
+		assert.Equal(t, 3, maxRunning, "Should run as many requests as allowed at the same time")
+	})
+
+	t.Run("Returns no embeddings for no texts", func(t *testing.T) {
+		embedder := newTestOpenAIEmbedder(t, OpenAIEmbedderOptions{}, func(request openai.EmbeddingRequest) {
+			t.Error("Should not send a request")
+		})
+
+		embeddings, err := embedder.EmbedBatch(context.Background(), nil)
+		assert.NoError(t, err, "Should not return an error")
+		assert.Empty(t, embeddings, "Should return no embeddings")
+	})
+}
+
+func Test_OpenAIEmbedderModel(t *testing.T) {
+	t.Run("Defaults to ada with 1536 dimensions", func(t *testing.T) {
+		embedder := newTestOpenAIEmbedder(t, OpenAIEmbedderOptions{}, func(request openai.EmbeddingRequest) {
+			assert.Equal(t, openai.AdaEmbeddingV2, request.Model, "Should request the default model")
+			assert.Zero(t, request.Dimensions, "Should not send dimensions for ada")
+		})
+
+		embedding, err := embedder.Embed(context.Background(), "Urteil")
+		assert.NoError(t, err, "Should not return an error")
+		assert.Len(t, embedding, 1536, "Should return 1536 dimensions")
+		assert.Equal(t, "text-embedding-ada-002", embedder.Model(), "Should return the model")
+	})
+
+	t.Run("Requests reduced dimensions", func(t *testing.T) {
+		embedder := newTestOpenAIEmbedder(t, OpenAIEmbedderOptions{Model: "text-embedding-3-large", Dimensions: 256}, func(request openai.EmbeddingRequest) {
+			assert.Equal(t, openai.LargeEmbedding3, request.Model, "Should request the configured model")
+			assert.Equal(t, 256, request.Dimensions, "Should request the configured dimensions")
+		})
+
+		embedding, err := embedder.Embed(context.Background(), "Urteil")
+		assert.NoError(t, err, "Should not return an error")
+		assert.Len(t, embedding, 256, "Should return 256 dimensions")
+	})
+
+	t.Run("Rejects unsupported configurations", func(t *testing.T) {
+		_, err := NewOpenAIEmbedder(OpenAIEmbedderOptions{Model: "text-embedding-ada-002", Dimensions: 256})
+		assert.ErrorIs(t, err, ErrUnsupportedDimensions, "Should not reduce the dimensions of ada")
+
+		_, err = NewOpenAIEmbedder(OpenAIEmbedderOptions{Model: "text-embedding-3-small", Dimensions: 3072})
+		assert.ErrorIs(t, err, ErrUnsupportedDimensions, "Should not increase the dimensions")
+
+		_, err = NewOpenAIEmbedder(OpenAIEmbedderOptions{Model: "unknown"})
+		assert.ErrorIs(t, err, ErrUnsupportedModel, "Should reject unknown models")
+	})
+}
@@ -0,0 +1,154 @@
+package embedder
+
+import (
+	"bufio"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Splits text into the pieces cl100k_base encodes independently. The original pattern ends with \s+(?!\S), Go
+// does not support lookaheads, so pieces emulates it.
+var pretokenizer = regexp.MustCompile(`(?i:'s|'t|'re|'ve|'m|'ll|'d)|[^\r\n\p{L}\p{N}]?\p{L}+|\p{N}{1,3}| ?[^\s\p{L}\p{N}]+[\r\n]*|\s*[\r\n]+|\s+`)
+
+// Counts tokens with byte pair encoding, the same way tiktoken does. Used for the OpenAI models, which all use
+// cl100k_base.
+type BPETokenizer struct {
+	ranks map[string]int
+}
+
+// Reads the ranks in the tiktoken format, every line contains a base64 encoded token and its rank
+func NewBPETokenizer(r io.Reader) (*BPETokenizer, error) {
+	ranks := make(map[string]int)
+
+	scanner := bufio.NewScanner(r)
+	line := 0
+
+	for scanner.Scan() {
+		line++
+
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		fields := strings.Fields(text)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("invalid rank in line %d: '%s'", line, text)
+		}
+
+		token, err := base64.StdEncoding.DecodeString(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid token in line %d: %w", line, err)
+		}
+
+		rank, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid rank in line %d: %w", line, err)
+		}
+
+		ranks[string(token)] = rank
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return &BPETokenizer{ranks: ranks}, nil
+}
+
+// Loads the ranks from a tiktoken file, e.g. cl100k_base.tiktoken
+func LoadBPETokenizer(path string) (*BPETokenizer, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return NewBPETokenizer(file)
+}
+
+// Returns the number of tokens of the text, has the signature of a token counter
+func (t *BPETokenizer) Count(text string) int {
+	count := 0
+
+	for _, piece := range pieces(text) {
+		count += t.countPiece(piece)
+	}
+
+	return count
+}
+
+// Merges the pair with the lowest rank until no pair is left, bytes without a rank count as a token each
+func (t *BPETokenizer) countPiece(piece string) int {
+	if _, ok := t.ranks[piece]; ok {
+		return 1
+	}
+
+	parts := make([]string, len(piece))
+
+	for i := 0; i < len(piece); i++ {
+		parts[i] = piece[i : i+1]
+	}
+
+	for len(parts) > 1 {
+		best := -1
+		bestRank := 0
+
+		for i := 0; i < len(parts)-1; i++ {
+			rank, ok := t.ranks[parts[i]+parts[i+1]]
+			if ok && (best < 0 || rank < bestRank) {
+				best = i
+				bestRank = rank
+			}
+		}
+
+		if best < 0 {
+			break
+		}
+
+		parts[best] += parts[best+1]
+		parts = append(parts[:best+1], parts[best+2:]...)
+	}
+
+	return len(parts)
+}
+
+// Splits the text with the pretokenizer. Whitespace in front of other text leaves its last character to the
+// following piece, as \s+(?!\S) does.
+func pieces(text string) []string {
+	var pieces []string
+
+	for start := 0; start < len(text); {
+		match := pretokenizer.FindStringIndex(text[start:])
+		if match == nil {
+			pieces = append(pieces, text[start:])
+			break
+		}
+
+		end := start + match[1]
+		piece := text[start:end]
+
+		if end < len(text) && isWhitespace(piece) && !strings.HasSuffix(piece, "\n") && !strings.HasSuffix(piece, "\r") {
+			if _, size := utf8.DecodeLastRuneInString(piece); size < len(piece) {
+				end -= size
+				piece = text[start:end]
+			}
+		}
+
+		pieces = append(pieces, piece)
+		start = end
+	}
+
+	return pieces
+}
+
+func isWhitespace(text string) bool {
+	return strings.TrimFunc(text, unicode.IsSpace) == ""
+}
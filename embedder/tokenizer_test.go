@@ -0,0 +1,52 @@
+package embedder
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Ranks of a, b, ab, " a", " ab" and the newline
+const testRanks = `YQ== 0
+Yg== 1
+YWI= 2
+IGE= 3
+IGFi 4
+Cg== 5
+`
+
+func Test_BPETokenizer(t *testing.T) {
+	tokenizer, err := NewBPETokenizer(strings.NewReader(testRanks))
+	assert.NoError(t, err, "Should read the ranks")
+
+	t.Run("Counts pieces with a rank as a single token", func(t *testing.T) {
+		assert.Equal(t, 2, tokenizer.Count("ab ab"), "Should count two tokens")
+	})
+
+	t.Run("Merges pairs with the lowest rank first", func(t *testing.T) {
+		assert.Equal(t, 2, tokenizer.Count("abab"), "Should merge into ab and ab")
+		assert.Equal(t, 2, tokenizer.Count(" abc"), "Should merge ab before \" a\"")
+	})
+
+	t.Run("Counts bytes without a rank as a token each", func(t *testing.T) {
+		assert.Equal(t, 3, tokenizer.Count("xyz"), "Should count every byte")
+		assert.Equal(t, 2, tokenizer.Count("ä"), "Should count both bytes of the rune")
+	})
+
+	t.Run("Fails for invalid ranks", func(t *testing.T) {
+		_, err := NewBPETokenizer(strings.NewReader("YQ== zero\n"))
+
+		assert.Error(t, err, "Should reject the rank")
+	})
+}
+
+func Test_Pieces(t *testing.T) {
+	t.Run("Splits words, numbers and punctuation", func(t *testing.T) {
+		assert.Equal(t, []string{"Der", " ", " Kläger", " hat", " ", "123", "4", " Euro", ".\n"}, pieces("Der  Kläger hat 1234 Euro.\n"), "Should split like cl100k_base")
+	})
+
+	t.Run("Keeps contractions and trailing whitespace", func(t *testing.T) {
+		assert.Equal(t, []string{"it", "'s", "  "}, pieces("it's  "), "Should not trim whitespace at the end")
+	})
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/JuliusMoehring/court-judgment-finder-crawler/embedder"
+	"github.com/JuliusMoehring/court-judgment-finder-crawler/logger"
+	vectorstore "github.com/JuliusMoehring/court-judgment-finder-crawler/vector-store"
+)
+
+// Layers newEmbedder wraps around the provider
+type embedderFlags int
+
+const (
+	// Limits the tokens of every text to the context window, see withContextWindow
+	EMBEDDER_CONTEXT_WINDOW embedderFlags = 1 << iota
+	// Limits the spent tokens to the configured budget, see withEmbeddingBudget
+	EMBEDDER_BUDGET
+	// Reuses cached embeddings, see withEmbeddingCache
+	EMBEDDER_CACHE
+
+	EMBEDDER_ALL = EMBEDDER_CONTEXT_WINDOW | EMBEDDER_BUDGET | EMBEDDER_CACHE
+)
+
+// Creates the configured embedder with the given layers, the budget applies to the texts sent to the provider
+// after they were split or truncated and only for texts that are not cached. Tokens are counted by counter. The
+// returned function releases the layers and logs what they report, in reverse order.
+func newEmbedder(ctx context.Context, logger logger.Logger, counter func(text string) int, flags embedderFlags) (embedder.Embedder, func(), error) {
+	e, err := newEmbeddingProvider(counter)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	closers := []func(){}
+
+	closeAll := func() {
+		for i := len(closers) - 1; i >= 0; i-- {
+			closers[i]()
+		}
+	}
+
+	layers := []struct {
+		flag embedderFlags
+		wrap func(e embedder.Embedder) (embedder.Embedder, func(), error)
+	}{
+		{EMBEDDER_BUDGET, func(e embedder.Embedder) (embedder.Embedder, func(), error) {
+			return withEmbeddingBudget(logger, e, counter)
+		}},
+		{EMBEDDER_CONTEXT_WINDOW, func(e embedder.Embedder) (embedder.Embedder, func(), error) {
+			return withContextWindow(logger, e, counter)
+		}},
+		{EMBEDDER_CACHE, func(e embedder.Embedder) (embedder.Embedder, func(), error) {
+			return withEmbeddingCache(ctx, logger, e)
+		}},
+	}
+
+	for _, layer := range layers {
+		if flags&layer.flag == 0 {
+			continue
+		}
+
+		wrapped, closeLayer, err := layer.wrap(e)
+		if err != nil {
+			closeAll()
+			return nil, nil, err
+		}
+
+		e = wrapped
+		closers = append(closers, closeLayer)
+	}
+
+	return e, closeAll, nil
+}
+
+// Creates the embedder configured by EMBEDDING_PROVIDER (openai, azure-openai, ollama or openai-compatible),
+// defaults to openai. All providers read EMBEDDING_MODEL, EMBEDDING_DIMENSIONS, EMBEDDING_CONCURRENCY (requests in
+// flight across all workers) and EMBEDDING_BATCH_SIZE (inputs per request). OpenAI and Azure additionally read
+// EMBEDDING_BATCH_TOKENS (tokens per request, counted by counter) and EMBEDDING_PROXY_URL. OpenAI reads
+// OPENAI_BASE_URL and OPENAI_ORGANIZATION_ID, Azure reads AZURE_OPENAI_ENDPOINT, AZURE_OPENAI_DEPLOYMENT,
+// AZURE_OPENAI_API_VERSION and either AZURE_OPENAI_API_KEY or AZURE_OPENAI_AD_TOKEN. Local providers read
+// EMBEDDING_BASE_URL and EMBEDDING_API_KEY.
+func newEmbeddingProvider(counter func(text string) int) (embedder.Embedder, error) {
+	model := os.Getenv("EMBEDDING_MODEL")
+
+	var dimensions, concurrency, batchSize, batchTokens int
+
+	for name, option := range map[string]*int{
+		"EMBEDDING_DIMENSIONS":   &dimensions,
+		"EMBEDDING_CONCURRENCY":  &concurrency,
+		"EMBEDDING_BATCH_SIZE":   &batchSize,
+		"EMBEDDING_BATCH_TOKENS": &batchTokens,
+	} {
+		value := os.Getenv(name)
+		if value == "" {
+			continue
+		}
+
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			return nil, fmt.Errorf("invalid %s: '%s'", name, value)
+		}
+
+		*option = parsed
+	}
+
+	localOptions := embedder.LocalEmbedderOptions{
+		BaseURL:        os.Getenv("EMBEDDING_BASE_URL"),
+		Model:          model,
+		Dimensions:     dimensions,
+		APIKey:         os.Getenv("EMBEDDING_API_KEY"),
+		MaxBatchInputs: batchSize,
+		MaxConcurrency: concurrency,
+	}
+
+	openAIOptions := embedder.OpenAIEmbedderOptions{
+		ProxyURL:       os.Getenv("EMBEDDING_PROXY_URL"),
+		Model:          model,
+		Dimensions:     dimensions,
+		MaxBatchInputs: batchSize,
+		MaxBatchTokens: batchTokens,
+		MaxConcurrency: concurrency,
+		TokenCounter:   counter,
+	}
+
+	switch provider := os.Getenv("EMBEDDING_PROVIDER"); provider {
+	case "", "openai":
+		openAIOptions.BaseURL = os.Getenv("OPENAI_BASE_URL")
+		openAIOptions.OrganizationID = os.Getenv("OPENAI_ORGANIZATION_ID")
+
+		return embedder.NewOpenAIEmbedder(openAIOptions)
+	case "azure-openai":
+		openAIOptions.APIKey = os.Getenv("AZURE_OPENAI_API_KEY")
+		token := os.Getenv("AZURE_OPENAI_AD_TOKEN")
+
+		if openAIOptions.APIKey != "" && token != "" {
+			return nil, fmt.Errorf("AZURE_OPENAI_API_KEY and AZURE_OPENAI_AD_TOKEN cannot be combined")
+		}
+
+		if token != "" {
+			openAIOptions.APIKey = token
+		}
+
+		return embedder.NewAzureOpenAIEmbedder(embedder.AzureOpenAIEmbedderOptions{
+			OpenAIEmbedderOptions: openAIOptions,
+			Endpoint:              os.Getenv("AZURE_OPENAI_ENDPOINT"),
+			Deployment:            os.Getenv("AZURE_OPENAI_DEPLOYMENT"),
+			APIVersion:            os.Getenv("AZURE_OPENAI_API_VERSION"),
+			EntraID:               token != "",
+		})
+	case "ollama":
+		return embedder.NewOllamaEmbedder(localOptions)
+	case "openai-compatible":
+		return embedder.NewOpenAICompatibleEmbedder(localOptions)
+	default:
+		return nil, fmt.Errorf("unknown embedding provider: '%s'", provider)
+	}
+}
+
+// Counts tokens with the BPE ranks in EMBEDDING_TOKENIZER_FILE (a tiktoken file like cl100k_base.tiktoken),
+// estimates them from the length of the text if it is not set
+func newTokenCounter() (func(text string) int, error) {
+	path := os.Getenv("EMBEDDING_TOKENIZER_FILE")
+	if path == "" {
+		return embedder.EstimateTokens, nil
+	}
+
+	tokenizer, err := embedder.LoadBPETokenizer(path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid EMBEDDING_TOKENIZER_FILE: %w", err)
+	}
+
+	return tokenizer.Count, nil
+}
+
+// Returns whether the provider serves the OpenAI models, which share their context window and prices
+func isOpenAIProvider(provider string) bool {
+	return provider == "" || provider == "openai" || provider == "azure-openai"
+}
+
+// Limits the tokens of every text to EMBEDDING_MAX_INPUT_TOKENS, defaults to the context window of the OpenAI
+// models and to no limit for other providers. EMBEDDING_OVERLONG_POLICY (split or truncate) decides how longer
+// texts are embedded, defaults to split. The returned function logs the number of overlong texts.
+func withContextWindow(logger logger.Logger, e embedder.Embedder, counter func(text string) int) (embedder.Embedder, func(), error) {
+	maxTokens := 0
+
+	if isOpenAIProvider(os.Getenv("EMBEDDING_PROVIDER")) {
+		maxTokens = embedder.OPENAI_MAX_INPUT_TOKENS
+	}
+
+	if value := os.Getenv("EMBEDDING_MAX_INPUT_TOKENS"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			return nil, nil, fmt.Errorf("invalid EMBEDDING_MAX_INPUT_TOKENS: '%s'", value)
+		}
+
+		maxTokens = parsed
+	}
+
+	policy := os.Getenv("EMBEDDING_OVERLONG_POLICY")
+
+	switch policy {
+	case "":
+		policy = embedder.OVERLONG_POLICY_SPLIT
+	case embedder.OVERLONG_POLICY_SPLIT, embedder.OVERLONG_POLICY_TRUNCATE:
+	default:
+		return nil, nil, fmt.Errorf("unknown overlong input policy: '%s'", policy)
+	}
+
+	if maxTokens == 0 {
+		return e, func() {}, nil
+	}
+
+	limited := embedder.NewContextWindowEmbedder(logger, e, counter, maxTokens, policy)
+
+	return limited, func() {
+		if overlong := limited.Overlong(); len(overlong) > 0 {
+			logger.Warnf("main", "%d texts exceeded the context window of %d tokens and were embedded with the %s policy", len(overlong), maxTokens, policy)
+		}
+	}, nil
+}
+
+// Fails if the vector store contains embeddings of a different model or with different dimensions
+func ensureEmbeddingModel(ctx context.Context, embedder embedder.Embedder, vectorStore vectorstore.VectorStore) error {
+	if err := vectorStore.EnsureEmbeddingModel(ctx, embedder.Model(), embedder.Dimensions()); err != nil {
+		return fmt.Errorf("invalid embedding configuration: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/JuliusMoehring/court-judgment-finder-crawler/bgh"
+	"github.com/JuliusMoehring/court-judgment-finder-crawler/download"
+	"github.com/JuliusMoehring/court-judgment-finder-crawler/logger"
+	vectorstore "github.com/JuliusMoehring/court-judgment-finder-crawler/vector-store"
+)
+
+type documentEstimate struct {
+	path   string
+	chunks int
+	tokens int
+}
+
+// Returns the chunks and tokens the link would be embedded with, nil if the link does not need processing.
+// Stored PDFs are read from the file storage and their sidecar is saved, so the next crawl does not extract
+// them again. Other PDFs are downloaded without saving them or their sidecar, as the estimate must not add
+// documents to the file storage, and a sidecar without its PDF would never be cleaned up by reconcile.
+func (p *Processor) estimateLink(ctx context.Context, link string, counter func(text string) int) (*documentEstimate, error) {
+	path, err := bgh.PathFromURL(link)
+	if err != nil {
+		p.logger.Errorf("estimate", "failed to create path from url '%s': %s", link, err)
+		return nil, nil
+	}
+
+	shouldProcess, err := p.shouldProcessLink(ctx, path)
+	if err != nil || !shouldProcess {
+		return nil, err
+	}
+
+	exists, err := p.fileStorage.Exists(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+
+	var data []byte
+
+	if exists {
+		data, err = p.fileStorage.Read(ctx, path)
+	} else {
+		data, err = p.downloader.Download(ctx, link)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	params, err := p.prepare(ctx, link, path, data, exists)
+	if err != nil {
+		return nil, err
+	}
+
+	estimate := &documentEstimate{path: path, chunks: len(params.Chunks)}
+
+	for _, chunk := range params.Chunks {
+		estimate.tokens += counter(chunk.Text)
+	}
+
+	return estimate, nil
+}
+
+// Estimates the tokens and the cost of embedding the documents a crawl would process, without embedding or
+// storing documents. Cached embeddings are not taken into account, so the estimate is an upper bound.
+func runEstimate(ctx context.Context, logger logger.Logger, args []string) error {
+	flags := flag.NewFlagSet("estimate", flag.ExitOnError)
+
+	storage := flags.String("storage", "s3", "file storage to read stored PDFs from (s3, supabase or local)")
+	verbose := flags.Bool("verbose", false, "print the estimate of every document")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	fileStorage, err := newFileStorage(ctx, logger, *storage)
+	if err != nil {
+		return err
+	}
+	runner, err := newCommandRunner()
+	if err != nil {
+		return err
+	}
+	pdfReader, err := newPDFReader(runner)
+	if err != nil {
+		return err
+	}
+	documentTimeout, err := newDocumentTimeout()
+	if err != nil {
+		return err
+	}
+	counter, err := newTokenCounter()
+	if err != nil {
+		return err
+	}
+	chunker, err := newChunker(counter)
+	if err != nil {
+		return err
+	}
+	// Nothing is embedded, the estimate covers the tokens before they are split or looked up in the cache
+	embedder, closeEmbedder, err := newEmbedder(ctx, logger, counter, 0)
+	if err != nil {
+		return err
+	}
+	defer closeEmbedder()
+	price, err := embeddingPrice(embedder.Model())
+	if err != nil {
+		return err
+	}
+	vectorStore := vectorstore.NewPostgresVectorStore(ctx, logger)
+	defer vectorStore.Close()
+
+	links, err := bgh.NewCrawler(logger).Crawl(ctx)
+	if err != nil {
+		return fmt.Errorf("could not crawl BGH: %s", err)
+	}
+
+	processor := NewProcessor(logger, download.NewSimpleDownloader(logger), fileStorage, pdfReader, newOCRReader(logger, runner), chunker, embedder, vectorStore, documentTimeout)
+
+	pending := make(chan string, len(links))
+
+	var (
+		wg        sync.WaitGroup
+		mutex     sync.Mutex
+		estimates []documentEstimate
+		failed    int
+	)
+
+	for i := 0; i < WORKERS; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for link := range pending {
+				estimate, err := processor.estimateLink(ctx, link, counter)
+
+				mutex.Lock()
+				if err != nil {
+					logger.Errorf("estimate", "failed estimating '%s': %s", link, err)
+					failed++
+				} else if estimate != nil {
+					estimates = append(estimates, *estimate)
+				}
+				mutex.Unlock()
+			}
+		}()
+	}
+
+	for _, link := range links {
+		pending <- link
+	}
+
+	close(pending)
+
+	wg.Wait()
+
+	sort.Slice(estimates, func(i, j int) bool {
+		return estimates[i].path < estimates[j].path
+	})
+
+	var chunks, tokens int
+
+	for _, estimate := range estimates {
+		chunks += estimate.chunks
+		tokens += estimate.tokens
+
+		if *verbose {
+			fmt.Printf("%s: %d chunks, %d tokens, $%.4f\n", estimate.path, estimate.chunks, estimate.tokens, embeddingCost(estimate.tokens, price))
+		}
+	}
+
+	fmt.Printf("%d pending documents, %d chunks, %d tokens, $%.4f with %s\n", len(estimates), chunks, tokens, embeddingCost(tokens, price), embedder.Model())
+
+	if failed > 0 {
+		return fmt.Errorf("%d documents could not be estimated", failed)
+	}
+
+	return nil
+}
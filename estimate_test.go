@@ -0,0 +1,43 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/JuliusMoehring/court-judgment-finder-crawler/embedder"
+	"github.com/JuliusMoehring/court-judgment-finder-crawler/pdf"
+)
+
+func Test_EstimateLink(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Estimates downloaded documents without saving them", func(t *testing.T) {
+		p := newTestProcessor(nil)
+
+		estimate, err := p.estimateLink(ctx, testLink, embedder.EstimateTokens)
+
+		assert.NoError(t, err, "Should not return an error")
+		assert.Equal(t, testPath, estimate.path, "Should return the path of the document")
+		assert.NotZero(t, estimate.chunks, "Should count the chunks")
+		assert.NotZero(t, estimate.tokens, "Should count the tokens")
+
+		paths, _ := p.fileStorage.List(ctx, "")
+		assert.Empty(t, paths, "Should not save the PDF or its sidecar")
+		assert.Empty(t, p.embedder.Texts(), "Should not embed anything")
+	})
+
+	t.Run("Saves the sidecar of stored documents", func(t *testing.T) {
+		p := newTestProcessor(nil)
+
+		assert.NoError(t, p.fileStorage.Save(ctx, testPDF, testPath), "Should save the PDF")
+
+		_, err := p.estimateLink(ctx, testLink, embedder.EstimateTokens)
+		assert.NoError(t, err, "Should not return an error")
+
+		exists, _ := p.fileStorage.Exists(ctx, pdf.SidecarPath(testPath))
+		assert.True(t, exists, "Should save the sidecar")
+		assert.Empty(t, p.downloader.Downloads(), "Should read the stored PDF")
+	})
+}
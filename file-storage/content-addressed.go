@@ -0,0 +1,156 @@
+package filestorage
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/JuliusMoehring/court-judgment-finder-crawler/logger"
+)
+
+const (
+	CONTENT_ADDRESSED_OBJECT_PREFIX = "sha256"
+	CONTENT_ADDRESSED_INDEX_PREFIX  = "index"
+)
+
+// Returns the hex encoded SHA-256 hash of the given data
+func ContentHash(data []byte) string {
+	hash := sha256.Sum256(data)
+
+	return hex.EncodeToString(hash[:])
+}
+
+// Returns the path under which the content with the given hash is stored, keeping the extension of the original path
+func ContentAddressedPath(hash string, path string) string {
+	return fmt.Sprintf("%s/%s%s", CONTENT_ADDRESSED_OBJECT_PREFIX, hash, filepath.Ext(path))
+}
+
+// Stores files under the hash of their content, so identical files are only stored once.
+// The original path is kept in an index object that points to the hash.
+type ContentAddressedFileStorage struct {
+	logger  logger.Logger
+	storage FileStorage
+}
+
+func NewContentAddressedFileStorage(logger logger.Logger, storage FileStorage) FileStorage {
+	return &ContentAddressedFileStorage{
+		logger:  logger,
+		storage: storage,
+	}
+}
+
+func (s *ContentAddressedFileStorage) indexPath(path string) string {
+	return fmt.Sprintf("%s/%s.sha256", CONTENT_ADDRESSED_INDEX_PREFIX, path)
+}
+
+// Returns the content hash that is stored in the index for the given path
+func (s *ContentAddressedFileStorage) Hash(ctx context.Context, path string) (string, error) {
+	data, err := s.storage.Read(ctx, s.indexPath(path))
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(data)), nil
+}
+
+// Only removes the path from the index, as the content might still be referenced by other paths
+func (s *ContentAddressedFileStorage) Delete(ctx context.Context, path string) error {
+	return s.storage.Delete(ctx, s.indexPath(path))
+}
+
+func (s *ContentAddressedFileStorage) Exists(ctx context.Context, path string) (bool, error) {
+	return s.storage.Exists(ctx, s.indexPath(path))
+}
+
+func (s *ContentAddressedFileStorage) List(ctx context.Context, prefix string) ([]string, error) {
+	indexPaths, err := s.storage.List(ctx, CONTENT_ADDRESSED_INDEX_PREFIX+"/"+prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make([]string, 0, len(indexPaths))
+
+	for _, indexPath := range indexPaths {
+		path := strings.TrimPrefix(indexPath, CONTENT_ADDRESSED_INDEX_PREFIX+"/")
+		paths = append(paths, strings.TrimSuffix(path, ".sha256"))
+	}
+
+	return paths, nil
+}
+
+func (s *ContentAddressedFileStorage) Read(ctx context.Context, path string) ([]byte, error) {
+	hash, err := s.Hash(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.storage.Read(ctx, ContentAddressedPath(hash, path))
+}
+
+// Returns the paths of stored contents that no path in the index points to anymore, e.g. because their
+// documents were deleted or replaced. Contents saved while listing might be returned before their index
+// entry is written, so this should not run concurrently with a crawl.
+func (s *ContentAddressedFileStorage) UnreferencedObjects(ctx context.Context) ([]string, error) {
+	indexPaths, err := s.storage.List(ctx, CONTENT_ADDRESSED_INDEX_PREFIX+"/")
+	if err != nil {
+		return nil, err
+	}
+
+	referenced := map[string]bool{}
+
+	for _, indexPath := range indexPaths {
+		path := strings.TrimSuffix(strings.TrimPrefix(indexPath, CONTENT_ADDRESSED_INDEX_PREFIX+"/"), ".sha256")
+
+		hash, err := s.Hash(ctx, path)
+		if err != nil {
+			return nil, err
+		}
+
+		referenced[ContentAddressedPath(hash, path)] = true
+	}
+
+	objectPaths, err := s.storage.List(ctx, CONTENT_ADDRESSED_OBJECT_PREFIX+"/")
+	if err != nil {
+		return nil, err
+	}
+
+	unreferenced := []string{}
+
+	for _, objectPath := range objectPaths {
+		if !referenced[objectPath] {
+			unreferenced = append(unreferenced, objectPath)
+		}
+	}
+
+	return unreferenced, nil
+}
+
+// Deletes stored content by its path as returned by UnreferencedObjects
+func (s *ContentAddressedFileStorage) DeleteObject(ctx context.Context, objectPath string) error {
+	if !strings.HasPrefix(objectPath, CONTENT_ADDRESSED_OBJECT_PREFIX+"/") {
+		return fmt.Errorf("not a content addressed object: '%s'", objectPath)
+	}
+
+	return s.storage.Delete(ctx, objectPath)
+}
+
+func (s *ContentAddressedFileStorage) Save(ctx context.Context, data []byte, path string) error {
+	hash := ContentHash(data)
+	objectPath := ContentAddressedPath(hash, path)
+
+	exists, err := s.storage.Exists(ctx, objectPath)
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		s.logger.Debugf("file-storage", "content of '%s' already stored as '%s'", path, objectPath)
+	} else if err := s.storage.Save(ctx, data, objectPath); err != nil {
+		return err
+	}
+
+	return s.storage.Save(ctx, []byte(hash), s.indexPath(path))
+}
@@ -0,0 +1,147 @@
+package filestorage
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/JuliusMoehring/court-judgment-finder-crawler/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+type memoryFileStorage struct {
+	mu sync.Mutex
+
+	files map[string][]byte
+}
+
+func newMemoryFileStorage() *memoryFileStorage {
+	return &memoryFileStorage{
+		files: map[string][]byte{},
+	}
+}
+
+func (m *memoryFileStorage) Delete(ctx context.Context, path string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.files, path)
+
+	return nil
+}
+
+func (m *memoryFileStorage) Exists(ctx context.Context, path string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	_, ok := m.files[path]
+
+	return ok, nil
+}
+
+func (m *memoryFileStorage) List(ctx context.Context, prefix string) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var paths []string
+
+	for path := range m.files {
+		if strings.HasPrefix(path, prefix) {
+			paths = append(paths, path)
+		}
+	}
+
+	sort.Strings(paths)
+
+	return paths, nil
+}
+
+func (m *memoryFileStorage) Read(ctx context.Context, path string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	data, ok := m.files[path]
+	if !ok {
+		return nil, ErrFileNotFound
+	}
+
+	return data, nil
+}
+
+func (m *memoryFileStorage) Save(ctx context.Context, data []byte, path string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.files[path] = data
+
+	return nil
+}
+
+func Test_ContentAddressedFileStorage(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Stores identical content only once", func(t *testing.T) {
+		backend := newMemoryFileStorage()
+		storage := NewContentAddressedFileStorage(logger.NewStdOutLogger(), backend)
+
+		data := []byte("%PDF-1.4 judgement")
+
+		assert.NoError(t, storage.Save(ctx, data, "judgements/bgh/2021/1_2_3.pdf"))
+		assert.NoError(t, storage.Save(ctx, data, "judgements/bgh/2021/1_2_4.pdf"))
+
+		objects := 0
+		for path := range backend.files {
+			if path == ContentAddressedPath(ContentHash(data), ".pdf") {
+				objects++
+			}
+		}
+
+		assert.Equal(t, 1, objects, "Should store the content once")
+		assert.Len(t, backend.files, 3, "Should store one object and two index entries")
+	})
+
+	t.Run("Reads the content through the index", func(t *testing.T) {
+		storage := NewContentAddressedFileStorage(logger.NewStdOutLogger(), newMemoryFileStorage())
+
+		data := []byte("%PDF-1.4 judgement")
+
+		assert.NoError(t, storage.Save(ctx, data, "judgements/bgh/2021/1_2_3.pdf"))
+
+		exists, err := storage.Exists(ctx, "judgements/bgh/2021/1_2_3.pdf")
+		assert.NoError(t, err, "Should not return an error")
+		assert.True(t, exists, "Should find the indexed path")
+
+		actual, err := storage.Read(ctx, "judgements/bgh/2021/1_2_3.pdf")
+		assert.NoError(t, err, "Should not return an error")
+		assert.Equal(t, data, actual, "Should return the stored content")
+	})
+
+	t.Run("Deletes contents no path refers to", func(t *testing.T) {
+		backend := newMemoryFileStorage()
+		storage := NewContentAddressedFileStorage(logger.NewStdOutLogger(), backend).(*ContentAddressedFileStorage)
+
+		assert.NoError(t, storage.Save(ctx, []byte("first"), "judgements/bgh/2021/1_2_3.pdf"))
+		assert.NoError(t, storage.Save(ctx, []byte("second"), "judgements/bgh/2021/1_2_3.pdf"))
+
+		unreferenced, err := storage.UnreferencedObjects(ctx)
+		assert.NoError(t, err, "Should not return an error")
+		assert.Equal(t, []string{ContentAddressedPath(ContentHash([]byte("first")), ".pdf")}, unreferenced, "Should return the replaced content")
+
+		assert.NoError(t, storage.DeleteObject(ctx, unreferenced[0]), "Should delete the content")
+		assert.Error(t, storage.DeleteObject(ctx, "index/judgements/bgh/2021/1_2_3.pdf.sha256"), "Should not delete index entries")
+
+		actual, err := storage.Read(ctx, "judgements/bgh/2021/1_2_3.pdf")
+		assert.NoError(t, err, "Should not return an error")
+		assert.Equal(t, []byte("second"), actual, "Should keep the referenced content")
+	})
+
+	t.Run("Returns `ErrFileNotFound` for unknown paths", func(t *testing.T) {
+		storage := NewContentAddressedFileStorage(logger.NewStdOutLogger(), newMemoryFileStorage())
+
+		_, err := storage.Read(ctx, "judgements/bgh/2021/unknown.pdf")
+
+		assert.ErrorIs(t, err, ErrFileNotFound, "Should return an `ErrFileNotFound` error")
+	})
+}
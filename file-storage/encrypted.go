@@ -0,0 +1,246 @@
+package filestorage
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/JuliusMoehring/court-judgment-finder-crawler/logger"
+)
+
+// Every encrypted object starts with this magic followed by the format version
+var ENCRYPTED_MAGIC = []byte("CJFE")
+
+const ENCRYPTED_FORMAT_VERSION = 1
+
+var (
+	ErrNotEncrypted   = errors.New("file is not encrypted")
+	ErrUnknownKeyID   = errors.New("unknown encryption key id")
+	ErrInvalidKeySize = errors.New("encryption key must be 16, 24 or 32 bytes long")
+)
+
+// Parses encryption keys in the format "id1:base64key,id2:base64key"
+func ParseEncryptionKeys(value string) (map[string][]byte, error) {
+	keys := map[string][]byte{}
+
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		id, encoded, found := strings.Cut(entry, ":")
+		if !found || id == "" {
+			return nil, fmt.Errorf("invalid encryption key entry: '%s'", entry)
+		}
+
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("invalid encryption key '%s': %w", id, err)
+		}
+
+		keys[id] = key
+	}
+
+	return keys, nil
+}
+
+// Compresses and encrypts files with AES-GCM before passing them to the underlying file storage.
+// The id of the key is stored in the header of every object, so old keys can still be used for
+// reading after the current key was rotated. The header is part of the object instead of metadata of
+// the backend on purpose: the local file storage has no metadata, and the key id travels with the
+// object when it is copied between backends or read without this decorator.
+type EncryptedFileStorage struct {
+	logger  logger.Logger
+	storage FileStorage
+
+	keyID string
+	aeads map[string]cipher.AEAD
+}
+
+func NewEncryptedFileStorage(logger logger.Logger, storage FileStorage, keyID string, keys map[string][]byte) (FileStorage, error) {
+	aeads := map[string]cipher.AEAD{}
+
+	for id, key := range keys {
+		if len(id) > 255 {
+			return nil, fmt.Errorf("encryption key id '%s' is too long", id)
+		}
+
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, ErrInvalidKeySize
+		}
+
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+
+		aeads[id] = aead
+	}
+
+	if _, ok := aeads[keyID]; !ok {
+		return nil, fmt.Errorf("%w: '%s'", ErrUnknownKeyID, keyID)
+	}
+
+	return &EncryptedFileStorage{
+		logger:  logger,
+		storage: storage,
+		keyID:   keyID,
+		aeads:   aeads,
+	}, nil
+}
+
+func (s *EncryptedFileStorage) Delete(ctx context.Context, path string) error {
+	return s.storage.Delete(ctx, path)
+}
+
+func (s *EncryptedFileStorage) Exists(ctx context.Context, path string) (bool, error) {
+	return s.storage.Exists(ctx, path)
+}
+
+func (s *EncryptedFileStorage) List(ctx context.Context, prefix string) ([]string, error) {
+	return s.storage.List(ctx, prefix)
+}
+
+func (s *EncryptedFileStorage) Read(ctx context.Context, path string) ([]byte, error) {
+	data, err := s.storage.Read(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := s.decrypt(data)
+	if err != nil {
+		s.logger.Errorf("file-storage", "failed decrypting '%s': %s", path, err)
+		return nil, err
+	}
+
+	return plaintext, nil
+}
+
+func (s *EncryptedFileStorage) Save(ctx context.Context, data []byte, path string) error {
+	ciphertext, err := s.encrypt(data)
+	if err != nil {
+		s.logger.Errorf("file-storage", "failed encrypting '%s': %s", path, err)
+		return err
+	}
+
+	return s.storage.Save(ctx, ciphertext, path)
+}
+
+// The header is authenticated as additional data, so the key id cannot be tampered with
+func (s *EncryptedFileStorage) header(keyID string) []byte {
+	header := append([]byte{}, ENCRYPTED_MAGIC...)
+	header = append(header, ENCRYPTED_FORMAT_VERSION, byte(len(keyID)))
+
+	return append(header, keyID...)
+}
+
+func (s *EncryptedFileStorage) encrypt(data []byte) ([]byte, error) {
+	var compressed bytes.Buffer
+
+	writer := gzip.NewWriter(&compressed)
+	if _, err := writer.Write(data); err != nil {
+		return nil, err
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	aead := s.aeads[s.keyID]
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	header := s.header(s.keyID)
+
+	result := make([]byte, 0, len(header)+len(nonce)+compressed.Len()+aead.Overhead())
+	result = append(result, header...)
+	result = append(result, nonce...)
+
+	return aead.Seal(result, nonce, compressed.Bytes(), header), nil
+}
+
+// Returns whether the stored file is encrypted with another key than the current one, e.g. after the key was
+// rotated. Plain files are not encrypted with the current key either.
+func (s *EncryptedFileStorage) EncryptedWithOtherKey(ctx context.Context, path string) (bool, error) {
+	data, err := s.storage.Read(ctx, path)
+	if err != nil {
+		return false, err
+	}
+
+	header, err := s.parseHeader(data)
+	if errors.Is(err, ErrNotEncrypted) {
+		return true, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	return string(header[len(ENCRYPTED_MAGIC)+2:]) != s.keyID, nil
+}
+
+// Returns the header of the encrypted data, see header
+func (s *EncryptedFileStorage) parseHeader(data []byte) ([]byte, error) {
+	prefixLength := len(ENCRYPTED_MAGIC) + 2
+
+	if len(data) < prefixLength || !bytes.Equal(data[:len(ENCRYPTED_MAGIC)], ENCRYPTED_MAGIC) {
+		return nil, ErrNotEncrypted
+	}
+
+	if version := data[len(ENCRYPTED_MAGIC)]; version != ENCRYPTED_FORMAT_VERSION {
+		return nil, fmt.Errorf("unsupported encryption format version: %d", version)
+	}
+
+	keyIDLength := int(data[len(ENCRYPTED_MAGIC)+1])
+	if len(data) < prefixLength+keyIDLength {
+		return nil, ErrNotEncrypted
+	}
+
+	return data[:prefixLength+keyIDLength], nil
+}
+
+func (s *EncryptedFileStorage) decrypt(data []byte) ([]byte, error) {
+	header, err := s.parseHeader(data)
+	if err != nil {
+		return nil, err
+	}
+
+	keyID := string(header[len(ENCRYPTED_MAGIC)+2:])
+
+	aead, ok := s.aeads[keyID]
+	if !ok {
+		return nil, fmt.Errorf("%w: '%s'", ErrUnknownKeyID, keyID)
+	}
+
+	rest := data[len(header):]
+
+	if len(rest) < aead.NonceSize() {
+		return nil, ErrNotEncrypted
+	}
+
+	compressed, err := aead.Open(nil, rest[:aead.NonceSize()], rest[aead.NonceSize():], header)
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	return io.ReadAll(reader)
+}
@@ -0,0 +1,101 @@
+package filestorage
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/JuliusMoehring/court-judgment-finder-crawler/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_EncryptedFileStorage(t *testing.T) {
+	ctx := context.Background()
+
+	oldKey := bytes.Repeat([]byte{1}, 32)
+	newKey := bytes.Repeat([]byte{2}, 32)
+
+	data := bytes.Repeat([]byte("Entscheidungsgründe "), 100)
+
+	t.Run("Encrypts the data before passing it to the underlying storage", func(t *testing.T) {
+		backend := newMemoryFileStorage()
+
+		storage, err := NewEncryptedFileStorage(logger.NewStdOutLogger(), backend, "old", map[string][]byte{"old": oldKey})
+		assert.NoError(t, err, "Should not return an error")
+
+		assert.NoError(t, storage.Save(ctx, data, "judgement.pdf"))
+
+		stored := backend.files["judgement.pdf"]
+		assert.False(t, bytes.Contains(stored, []byte("Entscheidungsgründe")), "Should not store the plaintext")
+		assert.Less(t, len(stored), len(data), "Should compress the data")
+
+		actual, err := storage.Read(ctx, "judgement.pdf")
+		assert.NoError(t, err, "Should not return an error")
+		assert.Equal(t, data, actual, "Should decrypt the stored data")
+	})
+
+	t.Run("Reads data encrypted with a rotated key", func(t *testing.T) {
+		backend := newMemoryFileStorage()
+
+		oldStorage, err := NewEncryptedFileStorage(logger.NewStdOutLogger(), backend, "old", map[string][]byte{"old": oldKey})
+		assert.NoError(t, err, "Should not return an error")
+		assert.NoError(t, oldStorage.Save(ctx, data, "judgement.pdf"))
+
+		newStorage, err := NewEncryptedFileStorage(logger.NewStdOutLogger(), backend, "new", map[string][]byte{"old": oldKey, "new": newKey})
+		assert.NoError(t, err, "Should not return an error")
+
+		actual, err := newStorage.Read(ctx, "judgement.pdf")
+		assert.NoError(t, err, "Should not return an error")
+		assert.Equal(t, data, actual, "Should decrypt with the old key")
+	})
+
+	t.Run("Returns `ErrUnknownKeyID` if the key is not configured", func(t *testing.T) {
+		backend := newMemoryFileStorage()
+
+		oldStorage, _ := NewEncryptedFileStorage(logger.NewStdOutLogger(), backend, "old", map[string][]byte{"old": oldKey})
+		assert.NoError(t, oldStorage.Save(ctx, data, "judgement.pdf"))
+
+		newStorage, _ := NewEncryptedFileStorage(logger.NewStdOutLogger(), backend, "new", map[string][]byte{"new": newKey})
+
+		_, err := newStorage.Read(ctx, "judgement.pdf")
+		assert.ErrorIs(t, err, ErrUnknownKeyID, "Should return an `ErrUnknownKeyID` error")
+	})
+
+	t.Run("Returns an error if the stored data was tampered with", func(t *testing.T) {
+		backend := newMemoryFileStorage()
+
+		storage, _ := NewEncryptedFileStorage(logger.NewStdOutLogger(), backend, "old", map[string][]byte{"old": oldKey})
+		assert.NoError(t, storage.Save(ctx, data, "judgement.pdf"))
+
+		stored := backend.files["judgement.pdf"]
+		stored[len(stored)-1] ^= 0xff
+
+		_, err := storage.Read(ctx, "judgement.pdf")
+		assert.Error(t, err, "Should return an error")
+	})
+
+	t.Run("Returns `ErrNotEncrypted` for plain files", func(t *testing.T) {
+		backend := newMemoryFileStorage()
+		backend.files["judgement.pdf"] = []byte("%PDF-1.4")
+
+		storage, _ := NewEncryptedFileStorage(logger.NewStdOutLogger(), backend, "old", map[string][]byte{"old": oldKey})
+
+		_, err := storage.Read(ctx, "judgement.pdf")
+		assert.ErrorIs(t, err, ErrNotEncrypted, "Should return an `ErrNotEncrypted` error")
+	})
+}
+
+func Test_ParseEncryptionKeys(t *testing.T) {
+	t.Run("Parses multiple keys", func(t *testing.T) {
+		keys, err := ParseEncryptionKeys("2024:AQEBAQ==, 2025:AgICAg==")
+
+		assert.NoError(t, err, "Should not return an error")
+		assert.Equal(t, map[string][]byte{"2024": {1, 1, 1, 1}, "2025": {2, 2, 2, 2}}, keys, "Should return all keys")
+	})
+
+	t.Run("Returns an error for entries without id", func(t *testing.T) {
+		_, err := ParseEncryptionKeys("AQEBAQ==")
+
+		assert.Error(t, err, "Should return an error")
+	})
+}
@@ -1,8 +1,17 @@
 package filestorage
 
-import "context"
+import (
+	"context"
+	"errors"
+)
+
+var ErrFileNotFound = errors.New("file not found")
 
 type FileStorage interface {
+	Delete(ctx context.Context, path string) error
 	Exists(ctx context.Context, path string) (bool, error)
+	// Returns the paths of all files whose path starts with the given prefix
+	List(ctx context.Context, prefix string) ([]string, error)
+	Read(ctx context.Context, path string) ([]byte, error)
 	Save(ctx context.Context, data []byte, path string) error
 }
@@ -2,18 +2,38 @@ package filestorage
 
 import (
 	"context"
+	"errors"
+	"io/fs"
 	"os"
+	"path/filepath"
+	"strings"
 )
 
 type LocalFileStorage struct {
+	root string
 }
 
-func NewLocalFileStorage() FileStorage {
-	return &LocalFileStorage{}
+func NewLocalFileStorage(root string) FileStorage {
+	return &LocalFileStorage{
+		root: root,
+	}
+}
+
+func (d *LocalFileStorage) fullPath(path string) string {
+	return filepath.Join(d.root, filepath.FromSlash(path))
+}
+
+func (d *LocalFileStorage) Delete(ctx context.Context, path string) error {
+	err := os.Remove(d.fullPath(path))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	return nil
 }
 
 func (d *LocalFileStorage) Exists(ctx context.Context, path string) (bool, error) {
-	_, err := os.Stat(path)
+	_, err := os.Stat(d.fullPath(path))
 	if err == nil {
 		return true, nil
 	}
@@ -21,8 +41,63 @@ func (d *LocalFileStorage) Exists(ctx context.Context, path string) (bool, error
 	return false, nil
 }
 
+func (d *LocalFileStorage) List(ctx context.Context, prefix string) ([]string, error) {
+	var paths []string
+
+	err := filepath.WalkDir(d.root, func(fullPath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if entry.IsDir() {
+			return nil
+		}
+
+		path, err := filepath.Rel(d.root, fullPath)
+		if err != nil {
+			return err
+		}
+
+		path = filepath.ToSlash(path)
+
+		if strings.HasPrefix(path, prefix) {
+			paths = append(paths, path)
+		}
+
+		return nil
+	})
+	if err != nil && errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return paths, nil
+}
+
+func (d *LocalFileStorage) Read(ctx context.Context, path string) ([]byte, error) {
+	data, err := os.ReadFile(d.fullPath(path))
+	if err != nil && errors.Is(err, os.ErrNotExist) {
+		return nil, ErrFileNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
 func (d *LocalFileStorage) Save(ctx context.Context, data []byte, path string) error {
-	f, err := os.Create(path)
+	fullPath := d.fullPath(path)
+
+	if err := os.MkdirAll(filepath.Dir(fullPath), os.ModePerm); err != nil {
+		return err
+	}
+
+	f, err := os.Create(fullPath)
 	if err != nil {
 		return err
 	}
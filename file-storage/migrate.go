@@ -0,0 +1,173 @@
+package filestorage
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/JuliusMoehring/court-judgment-finder-crawler/logger"
+)
+
+type MigrateOptions struct {
+	// Only files whose path starts with the prefix are migrated
+	Prefix  string
+	Workers int
+	// Only report what would be copied without writing to the destination
+	DryRun bool
+}
+
+type MigrateReport struct {
+	mu sync.Mutex
+
+	// Files that were copied, or would be copied in a dry run
+	Copied []string
+	// Files that already exist in the destination with the same checksum and encryption key
+	Skipped []string
+	Failed  map[string]error
+}
+
+func (r *MigrateReport) copied(path string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.Copied = append(r.Copied, path)
+}
+
+func (r *MigrateReport) skipped(path string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.Skipped = append(r.Skipped, path)
+}
+
+func (r *MigrateReport) failed(path string, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.Failed[path] = err
+}
+
+// Copies all files from one file storage to another. Files that already exist in the destination with the
+// same checksum are skipped, so an interrupted migration can simply be started again. Files the destination
+// would encrypt with another key are written again, so both storages may be the same backend to rotate the key.
+func Migrate(ctx context.Context, logger logger.Logger, from FileStorage, to FileStorage, options MigrateOptions) (*MigrateReport, error) {
+	paths, err := from.List(ctx, options.Prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Infof("file-storage", "found %d files to migrate", len(paths))
+
+	workers := options.Workers
+	if workers < 1 {
+		workers = 1
+	}
+
+	report := &MigrateReport{
+		Failed: map[string]error{},
+	}
+
+	jobs := make(chan string, len(paths))
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for path := range jobs {
+				copied, err := migrateFile(ctx, from, to, path, options.DryRun)
+				if err != nil {
+					logger.Errorf("file-storage", "failed migrating '%s': %s", path, err)
+					report.failed(path, err)
+					continue
+				}
+
+				if copied {
+					logger.Debugf("file-storage", "migrated '%s'", path)
+					report.copied(path)
+				} else {
+					logger.Debugf("file-storage", "skipping already migrated '%s'", path)
+					report.skipped(path)
+				}
+			}
+		}()
+	}
+
+	for _, path := range paths {
+		jobs <- path
+	}
+
+	close(jobs)
+
+	wg.Wait()
+
+	sort.Strings(report.Copied)
+	sort.Strings(report.Skipped)
+
+	return report, nil
+}
+
+// Returns whether the file was (or in a dry run would be) copied
+func migrateFile(ctx context.Context, from FileStorage, to FileStorage, path string, dryRun bool) (bool, error) {
+	exists, err := to.Exists(ctx, path)
+	if err != nil {
+		return false, err
+	}
+
+	if !exists && dryRun {
+		return true, nil
+	}
+
+	data, err := from.Read(ctx, path)
+	if err != nil {
+		return false, err
+	}
+
+	hash := ContentHash(data)
+
+	if exists {
+		existing, err := to.Read(ctx, path)
+		if err != nil {
+			return false, err
+		}
+
+		if ContentHash(existing) == hash {
+			stale, err := encryptedWithOtherKey(ctx, to, path)
+			if err != nil || !stale {
+				return false, err
+			}
+		}
+	}
+
+	if dryRun {
+		return true, nil
+	}
+
+	if err := to.Save(ctx, data, path); err != nil {
+		return false, err
+	}
+
+	saved, err := to.Read(ctx, path)
+	if err != nil {
+		return false, err
+	}
+
+	if ContentHash(saved) != hash {
+		return false, fmt.Errorf("checksum mismatch after copying '%s'", path)
+	}
+
+	return true, nil
+}
+
+// Returns whether the destination encrypts files and the existing file is not encrypted with its current key
+func encryptedWithOtherKey(ctx context.Context, to FileStorage, path string) (bool, error) {
+	encrypted, ok := to.(*EncryptedFileStorage)
+	if !ok {
+		return false, nil
+	}
+
+	return encrypted.EncryptedWithOtherKey(ctx, path)
+}
@@ -0,0 +1,133 @@
+package filestorage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/JuliusMoehring/court-judgment-finder-crawler/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Migrate(t *testing.T) {
+	ctx := context.Background()
+
+	newSource := func() *memoryFileStorage {
+		source := newMemoryFileStorage()
+		source.files["judgements/bgh/2021/1_2_3.pdf"] = []byte("first")
+		source.files["judgements/bgh/2021/1_2_4.pdf"] = []byte("second")
+		source.files["judgements/bgh/2022/1_2_5.pdf"] = []byte("third")
+
+		return source
+	}
+
+	t.Run("Copies all files to the destination", func(t *testing.T) {
+		source := newSource()
+		destination := newMemoryFileStorage()
+
+		report, err := Migrate(ctx, logger.NewStdOutLogger(), source, destination, MigrateOptions{Workers: 2})
+
+		assert.NoError(t, err, "Should not return an error")
+		assert.Len(t, report.Copied, 3, "Should copy all files")
+		assert.Empty(t, report.Failed, "Should not fail any file")
+		assert.Equal(t, source.files, destination.files, "Should have identical files in both storages")
+	})
+
+	t.Run("Skips files that were already migrated", func(t *testing.T) {
+		source := newSource()
+		destination := newMemoryFileStorage()
+		destination.files["judgements/bgh/2021/1_2_3.pdf"] = []byte("first")
+		destination.files["judgements/bgh/2021/1_2_4.pdf"] = []byte("outdated")
+
+		report, err := Migrate(ctx, logger.NewStdOutLogger(), source, destination, MigrateOptions{})
+
+		assert.NoError(t, err, "Should not return an error")
+		assert.Equal(t, []string{"judgements/bgh/2021/1_2_3.pdf"}, report.Skipped, "Should skip the identical file")
+		assert.Equal(t, []string{"judgements/bgh/2021/1_2_4.pdf", "judgements/bgh/2022/1_2_5.pdf"}, report.Copied, "Should copy changed and missing files")
+		assert.Equal(t, []byte("second"), destination.files["judgements/bgh/2021/1_2_4.pdf"], "Should overwrite the changed file")
+	})
+
+	t.Run("Resumes an interrupted migration", func(t *testing.T) {
+		source := newSource()
+		destination := &interruptedFileStorage{memoryFileStorage: newMemoryFileStorage(), saves: 2}
+
+		report, err := Migrate(ctx, logger.NewStdOutLogger(), source, destination, MigrateOptions{})
+
+		assert.NoError(t, err, "Should not return an error")
+		assert.Len(t, report.Copied, 2, "Should copy the files before the interruption")
+		assert.Len(t, report.Failed, 1, "Should fail the file after the interruption")
+
+		destination.saves = -1
+
+		report, err = Migrate(ctx, logger.NewStdOutLogger(), source, destination, MigrateOptions{})
+
+		assert.NoError(t, err, "Should not return an error")
+		assert.Len(t, report.Skipped, 2, "Should skip the files the destination already holds")
+		assert.Len(t, report.Copied, 1, "Should copy the remaining file")
+		assert.Empty(t, report.Failed, "Should not fail any file")
+		assert.Equal(t, source.files, destination.files, "Should have identical files in both storages")
+	})
+
+	t.Run("Does not write anything in a dry run", func(t *testing.T) {
+		destination := newMemoryFileStorage()
+
+		report, err := Migrate(ctx, logger.NewStdOutLogger(), newSource(), destination, MigrateOptions{Prefix: "judgements/bgh/2021/", DryRun: true})
+
+		assert.NoError(t, err, "Should not return an error")
+		assert.Len(t, report.Copied, 2, "Should report the files matching the prefix")
+		assert.Empty(t, destination.files, "Should not write to the destination")
+	})
+
+	t.Run("Encrypts files again with a rotated key in the same storage", func(t *testing.T) {
+		keys := map[string][]byte{"old": bytes.Repeat([]byte{1}, 32), "new": bytes.Repeat([]byte{2}, 32)}
+		backend := newMemoryFileStorage()
+
+		source, err := NewEncryptedFileStorage(logger.NewStdOutLogger(), backend, "old", keys)
+		assert.NoError(t, err, "Should not return an error")
+
+		for path, data := range newSource().files {
+			assert.NoError(t, source.Save(ctx, data, path), "Should not return an error")
+		}
+
+		destination, err := NewEncryptedFileStorage(logger.NewStdOutLogger(), backend, "new", keys)
+		assert.NoError(t, err, "Should not return an error")
+
+		report, err := Migrate(ctx, logger.NewStdOutLogger(), source, destination, MigrateOptions{})
+
+		assert.NoError(t, err, "Should not return an error")
+		assert.Len(t, report.Copied, 3, "Should write every file again")
+		assert.Empty(t, report.Failed, "Should not fail any file")
+
+		for path, data := range newSource().files {
+			stale, err := destination.(*EncryptedFileStorage).EncryptedWithOtherKey(ctx, path)
+			assert.NoError(t, err, "Should not return an error")
+			assert.False(t, stale, "Should encrypt the file with the new key")
+
+			actual, _ := destination.Read(ctx, path)
+			assert.Equal(t, data, actual, "Should keep the content")
+		}
+
+		report, err = Migrate(ctx, logger.NewStdOutLogger(), source, destination, MigrateOptions{})
+
+		assert.NoError(t, err, "Should not return an error")
+		assert.Len(t, report.Skipped, 3, "Should skip files encrypted with the new key")
+	})
+}
+
+// Fails every save after the given number of saves, unless saves is negative
+type interruptedFileStorage struct {
+	*memoryFileStorage
+
+	saves int
+}
+
+func (s *interruptedFileStorage) Save(ctx context.Context, data []byte, path string) error {
+	if s.saves == 0 {
+		return errors.New("interrupted")
+	}
+
+	s.saves--
+
+	return s.memoryFileStorage.Save(ctx, data, path)
+}
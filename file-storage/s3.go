@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"io"
 
 	"github.com/JuliusMoehring/court-judgment-finder-crawler/logger"
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -34,6 +35,17 @@ func NewS3FileStorage(ctx context.Context, logger logger.Logger, bucket string)
 	}
 }
 
+func (s *S3FileStorage) Delete(ctx context.Context, path string) error {
+	if _, err := s.client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(path),
+	}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func (s *S3FileStorage) Exists(ctx context.Context, path string) (bool, error) {
 	_, err := s.client.GetObjectAttributes(ctx, &s3.GetObjectAttributesInput{
 		Bucket: aws.String(s.bucket),
@@ -43,8 +55,8 @@ func (s *S3FileStorage) Exists(ctx context.Context, path string) (bool, error) {
 		},
 	})
 	if err != nil {
-		var err *types.NoSuchKey
-		if errors.As(err, &err) {
+		var noSuchKey *types.NoSuchKey
+		if errors.As(err, &noSuchKey) {
 			return false, nil
 		}
 
@@ -54,6 +66,46 @@ func (s *S3FileStorage) Exists(ctx context.Context, path string) (bool, error) {
 	return true, nil
 }
 
+func (s *S3FileStorage) List(ctx context.Context, prefix string) ([]string, error) {
+	var paths []string
+
+	paginator := s3.NewListObjectsV2Paginator(s.client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.bucket),
+		Prefix: aws.String(prefix),
+	})
+
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, object := range page.Contents {
+			paths = append(paths, aws.ToString(object.Key))
+		}
+	}
+
+	return paths, nil
+}
+
+func (s *S3FileStorage) Read(ctx context.Context, path string) ([]byte, error) {
+	output, err := s.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(path),
+	})
+	if err != nil {
+		var noSuchKey *types.NoSuchKey
+		if errors.As(err, &noSuchKey) {
+			return nil, ErrFileNotFound
+		}
+
+		return nil, err
+	}
+	defer output.Body.Close()
+
+	return io.ReadAll(output.Body)
+}
+
 func (s *S3FileStorage) Save(ctx context.Context, data []byte, path string) error {
 	if _, err := s.client.PutObject(ctx, &s3.PutObjectInput{
 		Bucket: aws.String(s.bucket),
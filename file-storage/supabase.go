@@ -3,10 +3,17 @@ package filestorage
 import (
 	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/JuliusMoehring/court-judgment-finder-crawler/logger"
-	"github.com/aws/smithy-go/ptr"
 	supabase "github.com/supabase-community/storage-go"
 )
 
@@ -14,35 +21,175 @@ type SupabaseFileStorage struct {
 	client *supabase.Client
 	bucket string
 
+	// Files are uploaded without the client, it sets the content type of an upload in the headers it shares
+	// with all requests, so concurrent uploads would overwrite each other's content type
+	httpClient *http.Client
+	url        string
+	apiKey     string
+
 	logger logger.Logger
 }
 
 func NewSupabaseFileStorage(logger logger.Logger, bucket string) FileStorage {
-	client := supabase.NewClient(os.Getenv("SUPABASE_STORAGE_URL"), os.Getenv("SUPABASE_PROJECT_SECRET_API_KEY"), nil)
+	url := os.Getenv("SUPABASE_STORAGE_URL")
+	apiKey := os.Getenv("SUPABASE_PROJECT_SECRET_API_KEY")
 
 	return &SupabaseFileStorage{
-		client: client,
-		bucket: bucket,
-		logger: logger,
+		client:     supabase.NewClient(url, apiKey, nil),
+		bucket:     bucket,
+		httpClient: &http.Client{},
+		url:        strings.TrimSuffix(url, "/"),
+		apiKey:     apiKey,
+		logger:     logger,
+	}
+}
+
+func (s *SupabaseFileStorage) Delete(ctx context.Context, path string) error {
+	if _, err := s.client.RemoveFile(s.bucket, []string{path}); err != nil {
+		return err
 	}
+
+	return nil
 }
 
+// Supabase has no call for the information of a single object, so the parent folder is listed instead
 func (s *SupabaseFileStorage) Exists(ctx context.Context, path string) (bool, error) {
-	_, err := s.client.ListFiles(s.bucket, path, supabase.FileSearchOptions{})
+	folder, name := "", path
+	if i := strings.LastIndex(path, "/"); i >= 0 {
+		folder, name = path[:i], path[i+1:]
+	}
+
+	for offset := 0; ; offset += SUPABASE_LIST_LIMIT {
+		objects, err := s.client.ListFiles(s.bucket, folder, supabase.FileSearchOptions{
+			Limit:  SUPABASE_LIST_LIMIT,
+			Offset: offset,
+		})
+		if err != nil {
+			return false, err
+		}
+
+		for _, object := range objects {
+			// Folders do not have an id
+			if object.Name == name && object.Id != "" {
+				return true, nil
+			}
+		}
+
+		if len(objects) < SUPABASE_LIST_LIMIT {
+			return false, nil
+		}
+	}
+}
+
+const SUPABASE_LIST_LIMIT = 100
+
+func (s *SupabaseFileStorage) List(ctx context.Context, prefix string) ([]string, error) {
+	// Supabase only lists a single folder level, so we have to walk the folders recursively
+	folder, namePrefix := "", prefix
+	if i := strings.LastIndex(prefix, "/"); i >= 0 {
+		folder, namePrefix = prefix[:i], prefix[i+1:]
+	}
+
+	var paths []string
+
+	for offset := 0; ; offset += SUPABASE_LIST_LIMIT {
+		objects, err := s.client.ListFiles(s.bucket, folder, supabase.FileSearchOptions{
+			Limit:  SUPABASE_LIST_LIMIT,
+			Offset: offset,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, object := range objects {
+			if !strings.HasPrefix(object.Name, namePrefix) {
+				continue
+			}
+
+			path := object.Name
+			if folder != "" {
+				path = folder + "/" + object.Name
+			}
+
+			// Folders do not have an id
+			if object.Id == "" {
+				children, err := s.List(ctx, path+"/")
+				if err != nil {
+					return nil, err
+				}
+
+				paths = append(paths, children...)
+				continue
+			}
+
+			paths = append(paths, path)
+		}
+
+		if len(objects) < SUPABASE_LIST_LIMIT {
+			break
+		}
+	}
+
+	return paths, nil
+}
+
+func (s *SupabaseFileStorage) Read(ctx context.Context, path string) ([]byte, error) {
+	data, err := s.client.DownloadFile(s.bucket, path)
 	if err != nil {
-		return false, err
+		var storageError *supabase.StorageError
+		if errors.As(err, &storageError) && (storageError.Status == http.StatusNotFound || strings.Contains(strings.ToLower(storageError.Message), "not found")) {
+			return nil, ErrFileNotFound
+		}
+
+		return nil, err
 	}
 
-	return false, nil
+	return data, nil
 }
 
 func (s *SupabaseFileStorage) Save(ctx context.Context, data []byte, path string) error {
-	_, err := s.client.UploadOrUpdateFile(s.bucket, path, bytes.NewReader(data), false, supabase.FileOptions{
-		ContentType: ptr.String("application/pdf"),
-	})
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("%s/object/%s/%s", s.url, s.bucket, path), bytes.NewReader(data))
 	if err != nil {
 		return err
 	}
 
-	return nil
+	request.Header.Set("Authorization", "Bearer "+s.apiKey)
+	request.Header.Set("Content-Type", contentType(path))
+	// Saving an existing path overwrites it like the other file storages do
+	request.Header.Set("x-upsert", "true")
+
+	response, err := s.httpClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode >= 200 && response.StatusCode < 300 {
+		return nil
+	}
+
+	body, _ := io.ReadAll(response.Body)
+
+	storageError := &supabase.StorageError{}
+	if err := json.Unmarshal(body, storageError); err != nil || storageError.Message == "" {
+		storageError.Message = strings.TrimSpace(string(body))
+	}
+
+	storageError.Status = response.StatusCode
+
+	return storageError
+}
+
+// Returns the content type by the extension of the path, e.g. for PDFs, sidecars and indexes
+func contentType(path string) string {
+	// Indexes of the content addressed file storage contain the hash as text
+	if filepath.Ext(path) == ".sha256" {
+		return "text/plain; charset=utf-8"
+	}
+
+	if contentType := mime.TypeByExtension(filepath.Ext(path)); contentType != "" {
+		return contentType
+	}
+
+	return "application/octet-stream"
 }
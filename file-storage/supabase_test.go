@@ -0,0 +1,70 @@
+package filestorage
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/JuliusMoehring/court-judgment-finder-crawler/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_SupabaseFileStorage(t *testing.T) {
+	t.Run("Saves concurrent uploads with their own content type", func(t *testing.T) {
+		var mu sync.Mutex
+		contentTypes := map[string]string{}
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"), "Should authorize the upload")
+			assert.Equal(t, "true", r.Header.Get("x-upsert"), "Should overwrite existing files")
+
+			contentTypes[strings.TrimPrefix(r.URL.Path, "/object/bucket/")] = r.Header.Get("Content-Type")
+		}))
+		defer server.Close()
+
+		t.Setenv("SUPABASE_STORAGE_URL", server.URL)
+		t.Setenv("SUPABASE_PROJECT_SECRET_API_KEY", "secret")
+
+		storage := NewSupabaseFileStorage(logger.NewStdOutLogger(), "bucket")
+
+		var wg sync.WaitGroup
+
+		for i := 0; i < 10; i++ {
+			for _, extension := range []string{".pdf", ".sha256"} {
+				wg.Add(1)
+				go func(path string) {
+					defer wg.Done()
+					assert.NoError(t, storage.Save(context.Background(), []byte("data"), path), "Should not return an error")
+				}(fmt.Sprintf("judgements/%d%s", i, extension))
+			}
+		}
+
+		wg.Wait()
+
+		assert.Len(t, contentTypes, 20, "Should upload every file")
+
+		for path, actual := range contentTypes {
+			assert.Equal(t, contentType(path), actual, "Should send the content type of %s", path)
+		}
+	})
+
+	t.Run("Returns the error of a failed upload", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"message":"new row violates row-level security policy"}`))
+		}))
+		defer server.Close()
+
+		t.Setenv("SUPABASE_STORAGE_URL", server.URL)
+
+		err := NewSupabaseFileStorage(logger.NewStdOutLogger(), "bucket").Save(context.Background(), []byte("data"), "judgement.pdf")
+		assert.EqualError(t, err, "new row violates row-level security policy", "Should return the message of the response")
+	})
+}
@@ -0,0 +1,86 @@
+package judgement
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/JuliusMoehring/court-judgment-finder-crawler/pdf"
+)
+
+// Court that decided a "Senatsurteil" or "Senatsbeschluss", i.e. the citing court itself
+const OWN_COURT = "BGH"
+
+const (
+	citedCourts = `BGH|BVerfG|BVerwG|BAG|BSG|BFH|EuGH`
+	// e.g. "12. März 2020" or "12.03.2020"
+	citedDate = datePattern + `|(\d{1,2})\.\s*(\d{1,2})\.\s*(\d{4})`
+	// e.g. "I ZR 126/18", "1 BvR 123/19" or "C-123/19"
+	citedFileNumber = `(?:(?:[IVX]+[a-z]?|\d{1,2})\s+)?[A-Z][A-Za-z]{1,5}\s+\d{1,4}/\d{2}|C-\d{1,4}/\d{2}`
+)
+
+// e.g. "BGH, Urteil vom 12. März 2020 - I ZR 126/18" or "Senatsbeschluss vom 4.5.2021 - I ZB 12/21"
+var citationPattern = regexp.MustCompile(`\b(?:(` + citedCourts + `),\s*(Urteil|Beschluss|Versäumnisurteil)|Senats(urteil|beschluss))\s+vom\s+(?:` + citedDate + `)\s*[-–—]\s*(` + citedFileNumber + `)`)
+
+// Reference to another decision found in the text of a judgement
+type Citation struct {
+	Court        string
+	DecisionType string
+	Date         *time.Time
+	// Aktenzeichen with normalized whitespace, e.g. "I ZR 126/18"
+	FileNumber string
+	// Page of the citing judgement the citation was found on
+	Page int
+	// The citation as it appears in the text
+	Text string
+}
+
+// Returns the citations of all pages, every cited decision is only returned once per page
+func ExtractCitations(pages []pdf.NormalizedPage) []Citation {
+	var citations []Citation
+
+	for _, page := range pages {
+		seen := map[string]bool{}
+
+		for _, match := range citationPattern.FindAllStringSubmatch(page.Text, -1) {
+			citation := Citation{
+				Court:        match[1],
+				DecisionType: match[2],
+				FileNumber:   strings.Join(strings.Fields(match[10]), " "),
+				Page:         page.Page,
+				Text:         strings.Join(strings.Fields(match[0]), " "),
+			}
+
+			if citation.Court == "" {
+				citation.Court = OWN_COURT
+				citation.DecisionType = strings.ToUpper(match[3][:1]) + match[3][1:]
+			}
+
+			if match[4] != "" {
+				citation.Date = parseDate(match[4:7])
+			} else {
+				citation.Date = parseNumericDate(match[7:10])
+			}
+
+			key := citation.Court + " " + citation.FileNumber
+			if seen[key] {
+				continue
+			}
+
+			seen[key] = true
+			citations = append(citations, citation)
+		}
+	}
+
+	return citations
+}
+
+// Parses the day, month and year of dates like "12.03.2020"
+func parseNumericDate(match []string) *time.Time {
+	date, err := time.Parse("2.1.2006", strings.Join(match, "."))
+	if err != nil {
+		return nil
+	}
+
+	return &date
+}
@@ -0,0 +1,44 @@
+package judgement
+
+import (
+	"testing"
+	"time"
+
+	"github.com/JuliusMoehring/court-judgment-finder-crawler/pdf"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ExtractCitations(t *testing.T) {
+	t.Run("Extracts citations of decisions", func(t *testing.T) {
+		citations := ExtractCitations([]pdf.NormalizedPage{
+			{Page: 3, Text: "Nach ständiger Rechtsprechung (BGH, Urteil vom 12. März 2020 - I ZR\n126/18, GRUR 2020, 647 Rn. 12; BVerfG, Beschluss vom 4.5.2021 – 1 BvR 123/19) ist das anders."},
+			{Page: 4, Text: "Wie der Senat entschieden hat (Senatsbeschluss vom 1. Juni 2017 - I ZB 12/16, juris Rn. 3)."},
+		})
+
+		march := time.Date(2020, time.March, 12, 0, 0, 0, 0, time.UTC)
+		may := time.Date(2021, time.May, 4, 0, 0, 0, 0, time.UTC)
+		june := time.Date(2017, time.June, 1, 0, 0, 0, 0, time.UTC)
+
+		assert.Equal(t, []Citation{
+			{Court: "BGH", DecisionType: "Urteil", Date: &march, FileNumber: "I ZR 126/18", Page: 3, Text: "BGH, Urteil vom 12. März 2020 - I ZR 126/18"},
+			{Court: "BVerfG", DecisionType: "Beschluss", Date: &may, FileNumber: "1 BvR 123/19", Page: 3, Text: "BVerfG, Beschluss vom 4.5.2021 – 1 BvR 123/19"},
+			{Court: "BGH", DecisionType: "Beschluss", Date: &june, FileNumber: "I ZB 12/16", Page: 4, Text: "Senatsbeschluss vom 1. Juni 2017 - I ZB 12/16"},
+		}, citations, "Should return all citations")
+	})
+
+	t.Run("Returns every cited decision once per page", func(t *testing.T) {
+		citations := ExtractCitations([]pdf.NormalizedPage{
+			{Page: 1, Text: "BGH, Urteil vom 12. März 2020 - I ZR 126/18; BGH, Urteil vom 12. März 2020 - I ZR 126/18"},
+		})
+
+		assert.Len(t, citations, 1, "Should return a single citation")
+	})
+
+	t.Run("Extracts citations of the EuGH", func(t *testing.T) {
+		citations := ExtractCitations([]pdf.NormalizedPage{
+			{Page: 1, Text: "(vgl. EuGH, Urteil vom 29. Juli 2019 - C-476/17, GRUR 2019, 929)"},
+		})
+
+		assert.Equal(t, "C-476/17", citations[0].FileNumber, "Should return the case number")
+	})
+}
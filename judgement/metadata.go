@@ -0,0 +1,216 @@
+package judgement
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DECISION_TYPE_URTEIL            = "Urteil"
+	DECISION_TYPE_BESCHLUSS         = "Beschluss"
+	DECISION_TYPE_VERSAEUMNISURTEIL = "Versäumnisurteil"
+)
+
+// Courts as they appear in the header of a decision, mapped to the abbreviation used in file paths
+var courts = map[string]string{
+	"BUNDESGERICHTSHOF": "BGH",
+}
+
+var months = map[string]time.Month{
+	"Januar":    time.January,
+	"Februar":   time.February,
+	"März":      time.March,
+	"April":     time.April,
+	"Mai":       time.May,
+	"Juni":      time.June,
+	"Juli":      time.July,
+	"August":    time.August,
+	"September": time.September,
+	"Oktober":   time.October,
+	"November":  time.November,
+	"Dezember":  time.December,
+}
+
+const datePattern = `(\d{1,2})\.\s*(Januar|Februar|März|April|Mai|Juni|Juli|August|September|Oktober|November|Dezember)\s+(\d{4})`
+
+var (
+	// e.g. "I ZR 126/18", "3 StR 45/21" or "KZR 12/19"
+	fileNumberPattern = regexp.MustCompile(`\b((?:[IVX]+[a-z]?|\d{1,2})\s+)?([A-Z][A-Za-z]{1,5})\s+(\d{1,4}/\d{2})\b`)
+	ecliPattern       = regexp.MustCompile(`\bECLI:DE:BGH:(\d{4}):(\d{2})(\d{2})(\d{2})([UB])[A-Z0-9.]+`)
+	senatePattern     = regexp.MustCompile(`\b(?:([IVX]+[a-z]?|\d{1,2})\.\s+)?(Zivilsenat|Strafsenat|Kartellsenat|Senat für [A-ZÄÖÜ][\wäöüß-]+)`)
+	flagPattern       = regexp.MustCompile(`\b(Nachschlagewerk|BGHZ|BGHR)\s*:\s*(ja|nein)\b`)
+	// Lines like "UWG § 5 Abs. 1" or "GG Art. 5 Abs. 1 Satz 2" in front of the Leitsatz, the abbreviation of the
+	// law has at least two capital letters and the line does not end with a period, so sentences are not matched
+	normPattern = regexp.MustCompile(`^[A-ZÄÖÜ][A-Za-zÄÖÜäöüß]*[A-Z][\wÄÖÜäöüß/-]*(?:\s+[\wÄÖÜäöüß/-]+){0,2}\s+(?:§§?|Artt?\.)\s*\d(?:.*[^.])?$`)
+
+	// The date of the decision in order of reliability
+	decisionDatePatterns = []*regexp.Regexp{
+		regexp.MustCompile(`BGH,\s*(?:Urteil|Beschluss|Versäumnisurteil|Teilurteil)\s+vom\s+` + datePattern),
+		regexp.MustCompile(`Verkündet\s+am:?\s*` + datePattern),
+		regexp.MustCompile(`\bhat\s+am\s+` + datePattern),
+	}
+	decisionTypePattern = regexp.MustCompile(`BGH,\s*(Urteil|Beschluss|Versäumnisurteil)\s+vom\b`)
+)
+
+// Header lines compared without spaces, older decisions use spaced headings like "U R T E I L"
+var decisionTypeHeadings = map[string]string{
+	"URTEIL":           DECISION_TYPE_URTEIL,
+	"BESCHLUSS":        DECISION_TYPE_BESCHLUSS,
+	"VERSÄUMNISURTEIL": DECISION_TYPE_VERSAEUMNISURTEIL,
+}
+
+// Metadata read from the header of a decision, zero values are unknown
+type Metadata struct {
+	// Aktenzeichen, e.g. "I ZR 126/18"
+	FileNumber   string
+	Date         *time.Time
+	DecisionType string
+	// Abbreviation of the court, e.g. "BGH"
+	Court string
+	// e.g. "I. Zivilsenat"
+	Senate string
+	ECLI   string
+
+	Nachschlagewerk *bool
+	BGHZ            *bool
+	BGHR            *bool
+
+	// Norms listed in front of the Leitsatz, e.g. "UWG § 5 Abs. 1"
+	Norms []string
+}
+
+// Extracts the metadata from the Rubrum and Leitsatz of a decision
+func ExtractMetadata(sections []Section) *Metadata {
+	var header []string
+	var leitsatz []string
+
+	for _, section := range sections {
+		switch section.Type {
+		case SECTION_RUBRUM:
+			header = append(header, section.Text)
+		case SECTION_LEITSATZ:
+			header = append(header, section.Text)
+			leitsatz = append(leitsatz, section.Text)
+		}
+	}
+
+	text := strings.Join(header, "\n")
+	metadata := &Metadata{}
+
+	for _, line := range strings.Split(text, "\n") {
+		key := strings.ReplaceAll(strings.TrimSpace(line), " ", "")
+
+		if court, ok := courts[key]; ok && metadata.Court == "" {
+			metadata.Court = court
+		}
+
+		if decisionType, ok := decisionTypeHeadings[key]; ok && metadata.DecisionType == "" {
+			metadata.DecisionType = decisionType
+		}
+	}
+
+	if match := decisionTypePattern.FindStringSubmatch(text); match != nil {
+		metadata.DecisionType = match[1]
+	}
+
+	if match := fileNumberPattern.FindStringSubmatch(text); match != nil {
+		metadata.FileNumber = strings.TrimSpace(match[1] + match[2] + " " + match[3])
+	}
+
+	if match := senatePattern.FindStringSubmatch(text); match != nil {
+		metadata.Senate = match[0]
+	}
+
+	for _, match := range flagPattern.FindAllStringSubmatch(text, -1) {
+		value := match[2] == "ja"
+
+		switch match[1] {
+		case "Nachschlagewerk":
+			metadata.Nachschlagewerk = &value
+		case "BGHZ":
+			metadata.BGHZ = &value
+		case "BGHR":
+			metadata.BGHR = &value
+		}
+	}
+
+	for _, pattern := range decisionDatePatterns {
+		if match := pattern.FindStringSubmatch(text); match != nil {
+			metadata.Date = parseDate(match[len(match)-3:])
+			break
+		}
+	}
+
+	if match := ecliPattern.FindStringSubmatch(text); match != nil {
+		metadata.ECLI = match[0]
+
+		if metadata.Date == nil {
+			metadata.Date = parseECLIDate(match[1], match[2], match[3])
+		}
+
+		// ECLIs only distinguish between Urteil and Beschluss
+		if metadata.DecisionType == "" {
+			metadata.DecisionType = map[string]string{"U": DECISION_TYPE_URTEIL, "B": DECISION_TYPE_BESCHLUSS}[match[5]]
+		}
+	}
+
+	for _, section := range leitsatz {
+		for _, line := range strings.Split(section, "\n") {
+			line = strings.TrimSpace(line)
+
+			if !normPattern.MatchString(line) {
+				break
+			}
+
+			metadata.Norms = append(metadata.Norms, line)
+		}
+	}
+
+	return metadata
+}
+
+// Parses the day, german month name and year matched by datePattern
+func parseDate(match []string) *time.Time {
+	day, err := strconv.Atoi(match[0])
+	if err != nil {
+		return nil
+	}
+
+	year, err := strconv.Atoi(match[2])
+	if err != nil {
+		return nil
+	}
+
+	date := time.Date(year, months[match[1]], day, 0, 0, 0, 0, time.UTC)
+
+	return &date
+}
+
+// ECLIs contain the year and the date as "DDMMYY", e.g. "ECLI:DE:BGH:2020:120320UIZR126.18.0"
+func parseECLIDate(year string, day string, month string) *time.Time {
+	date, err := time.Parse("2006-01-02", fmt.Sprintf("%s-%s-%s", year, month, day))
+	if err != nil {
+		return nil
+	}
+
+	return &date
+}
+
+// Compares the metadata with the court and year the crawler found the decision under and returns a description
+// of every difference
+func (m *Metadata) Mismatches(court string, year int) []string {
+	var mismatches []string
+
+	if m.Court != "" && court != "" && !strings.EqualFold(m.Court, court) {
+		mismatches = append(mismatches, fmt.Sprintf("court is '%s', crawler found '%s'", m.Court, court))
+	}
+
+	if m.Date != nil && year > 0 && m.Date.Year() != year {
+		mismatches = append(mismatches, fmt.Sprintf("decision year is %d, crawler found %d", m.Date.Year(), year))
+	}
+
+	return mismatches
+}
@@ -0,0 +1,72 @@
+package judgement
+
+import (
+	"testing"
+	"time"
+
+	"github.com/JuliusMoehring/court-judgment-finder-crawler/pdf"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ExtractMetadata(t *testing.T) {
+	t.Run("Extracts the metadata of a judgement", func(t *testing.T) {
+		sections := Parse(pdf.Normalize([]string{
+			"ECLI:DE:BGH:2020:120320UIZR126.18.0\n\nBUNDESGERICHTSHOF\n\nIM NAMEN DES VOLKES\n\nU R T E I L\n\n" +
+				"I ZR 126/18\n\nVerkündet am:\n12. März 2020\n\nNachschlagewerk: ja\nBGHZ: nein\nBGHR: ja\n\n" +
+				"UWG § 5 Abs. 1\nBGB §§ 823, 1004\n\nDer Anspruch aus § 5 UWG setzt eine Irreführung voraus.\n\n" +
+				"BGH, Urteil vom 12. März 2020 - I ZR 126/18 - OLG Köln\n\n" +
+				"Der I. Zivilsenat des Bundesgerichtshofs hat auf die mündliche Verhandlung vom 6. Februar 2020 für Recht erkannt:\n\n" +
+				"Die Revision wird zurückgewiesen.\n",
+		}))
+
+		metadata := ExtractMetadata(sections)
+
+		yes, no := true, false
+		date := time.Date(2020, time.March, 12, 0, 0, 0, 0, time.UTC)
+
+		assert.Equal(t, &Metadata{
+			FileNumber:      "I ZR 126/18",
+			Date:            &date,
+			DecisionType:    DECISION_TYPE_URTEIL,
+			Court:           "BGH",
+			Senate:          "I. Zivilsenat",
+			ECLI:            "ECLI:DE:BGH:2020:120320UIZR126.18.0",
+			Nachschlagewerk: &yes,
+			BGHZ:            &no,
+			BGHR:            &yes,
+			Norms:           []string{"UWG § 5 Abs. 1", "BGB §§ 823, 1004"},
+		}, metadata, "Should return all metadata")
+	})
+
+	t.Run("Extracts the metadata of a decision", func(t *testing.T) {
+		sections := Parse(pdf.Normalize([]string{
+			"BUNDESGERICHTSHOF\n\nBESCHLUSS\n\n3 StR 45/21\n\n" +
+				"Der 3. Strafsenat des Bundesgerichtshofs hat am 4. Mai 2021 beschlossen:\n\nDie Revision wird verworfen.\n",
+		}))
+
+		metadata := ExtractMetadata(sections)
+
+		assert.Equal(t, "3 StR 45/21", metadata.FileNumber, "Should return the Aktenzeichen")
+		assert.Equal(t, DECISION_TYPE_BESCHLUSS, metadata.DecisionType, "Should return the decision type")
+		assert.Equal(t, "3. Strafsenat", metadata.Senate, "Should return the senate")
+		assert.Equal(t, time.Date(2021, time.May, 4, 0, 0, 0, 0, time.UTC), *metadata.Date, "Should return the date of the decision")
+		assert.Nil(t, metadata.Nachschlagewerk, "Should return unknown flags as nil")
+		assert.Empty(t, metadata.Norms, "Should return no norms")
+	})
+
+	t.Run("Falls back to the date of the ECLI", func(t *testing.T) {
+		metadata := ExtractMetadata([]Section{{Type: SECTION_RUBRUM, Text: "ECLI:DE:BGH:2019:050919BIZB12.19.0"}})
+
+		assert.Equal(t, time.Date(2019, time.September, 5, 0, 0, 0, 0, time.UTC), *metadata.Date, "Should return the date of the ECLI")
+		assert.Equal(t, DECISION_TYPE_BESCHLUSS, metadata.DecisionType, "Should return the decision type of the ECLI")
+	})
+}
+
+func Test_Metadata_Mismatches(t *testing.T) {
+	date := time.Date(2020, time.March, 12, 0, 0, 0, 0, time.UTC)
+	metadata := &Metadata{Court: "BGH", Date: &date}
+
+	assert.Empty(t, metadata.Mismatches("bgh", 2020), "Should return no mismatches")
+	assert.Equal(t, []string{"court is 'BGH', crawler found 'bverfg'", "decision year is 2020, crawler found 2021"}, metadata.Mismatches("bverfg", 2021), "Should return all mismatches")
+	assert.Empty(t, (&Metadata{}).Mismatches("bgh", 2020), "Should ignore unknown metadata")
+}
@@ -0,0 +1,189 @@
+package judgement
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/JuliusMoehring/court-judgment-finder-crawler/pdf"
+)
+
+type SectionType string
+
+const (
+	SECTION_RUBRUM     SectionType = "rubrum"
+	SECTION_LEITSATZ   SectionType = "leitsatz"
+	SECTION_TENOR      SectionType = "tenor"
+	SECTION_TATBESTAND SectionType = "tatbestand"
+	// Entscheidungsgründe of judgements as well as the Gründe of decisions without a separate Tatbestand
+	SECTION_GRUENDE SectionType = "gruende"
+)
+
+// Headings are only recognized on short lines, so sentences starting with the same word are not mistaken for them
+const MAX_HEADING_LENGTH = 40
+
+var (
+	// Headings are compared without spaces, older decisions use spaced headings like "T a t b e s t a n d"
+	headingPatterns = map[SectionType]*regexp.Regexp{
+		SECTION_LEITSATZ:   regexp.MustCompile(`^Leits(?:atz|ätze):?$`),
+		SECTION_TENOR:      regexp.MustCompile(`^Tenor:?$`),
+		SECTION_TATBESTAND: regexp.MustCompile(`^Tatbestand:?$`),
+		SECTION_GRUENDE:    regexp.MustCompile(`^(?:Entscheidungsgründe|Gründe):?$`),
+	}
+	// The Tenor follows the last sentence of the Rubrum, e.g. "... für Recht erkannt:"
+	tenorStartPattern = regexp.MustCompile(`(?:für Recht erkannt|beschlossen|entschieden):$`)
+	// The Leitsatz of decisions marked for the Nachschlagewerk follows the "BGHR: ja" line without a heading,
+	// decisions marked with "BGHR: nein" have none
+	leitsatzStartPattern = regexp.MustCompile(`^BGHR\s*:\s*ja$`)
+	// The Leitsatz ends with the citation of the decision, e.g. "BGH, Urteil vom 12. März 2020 - I ZR 126/18 - OLG Köln"
+	leitsatzEndPattern = regexp.MustCompile(`^BGH,\s*(?:Urteil|Beschluss|Versäumnisurteil|Teilurteil)\s+vom\b`)
+)
+
+type Section struct {
+	Type SectionType
+	Text string
+	// Pages the section spans, starting at 1
+	StartPage int
+	EndPage   int
+	// First and last Randnummer within the section, zero if the section has none
+	StartMarginNumber int
+	EndMarginNumber   int
+}
+
+type sectionBuilder struct {
+	section Section
+	text    strings.Builder
+}
+
+func (b *sectionBuilder) add(line string, page int, marginNumber int) {
+	if b.text.Len() > 0 {
+		b.text.WriteString("\n")
+	}
+
+	b.text.WriteString(line)
+
+	if line != "" {
+		if b.section.StartPage == 0 {
+			b.section.StartPage = page
+		}
+
+		b.section.EndPage = page
+	}
+
+	if marginNumber > 0 {
+		if b.section.StartMarginNumber == 0 {
+			b.section.StartMarginNumber = marginNumber
+		}
+
+		b.section.EndMarginNumber = marginNumber
+	}
+}
+
+// Splits the normalized pages of a decision into its sections. Text before the first recognized section is part of
+// the Rubrum, decisions without any recognized heading are returned as a single Rubrum.
+func Parse(pages []pdf.NormalizedPage) []Section {
+	var sections []Section
+
+	current := &sectionBuilder{section: Section{Type: SECTION_RUBRUM}}
+	hasLeitsatz := false
+
+	next := func(sectionType SectionType) {
+		current.section.Text = strings.TrimSpace(current.text.String())
+
+		if current.section.Text != "" {
+			sections = append(sections, current.section)
+		}
+
+		current = &sectionBuilder{section: Section{Type: sectionType}}
+	}
+
+	for _, page := range pages {
+		marginNumbers := map[int]int{}
+
+		for _, marginNumber := range page.MarginNumbers {
+			marginNumbers[marginNumber.Offset] = marginNumber.Number
+		}
+
+		offset := 0
+
+		for _, line := range strings.Split(page.Text, "\n") {
+			marginNumber := marginNumbers[offset]
+			offset += len(line) + 1
+
+			if sectionType, ok := heading(line); ok {
+				if sectionType == SECTION_LEITSATZ {
+					hasLeitsatz = true
+				}
+
+				next(sectionType)
+				continue
+			}
+
+			switch current.section.Type {
+			case SECTION_RUBRUM:
+				if tenorStartPattern.MatchString(line) {
+					current.add(line, page.Page, marginNumber)
+					next(SECTION_TENOR)
+					continue
+				}
+
+				if !hasLeitsatz && leitsatzStartPattern.MatchString(line) {
+					hasLeitsatz = true
+					current.add(line, page.Page, marginNumber)
+					next(SECTION_LEITSATZ)
+					continue
+				}
+			case SECTION_LEITSATZ:
+				if leitsatzEndPattern.MatchString(line) {
+					next(SECTION_RUBRUM)
+				}
+
+				// The Leitsatz ends at the latest with the Rubrum, even without the citation of the decision
+				if tenorStartPattern.MatchString(line) {
+					next(SECTION_RUBRUM)
+					current.add(line, page.Page, marginNumber)
+					next(SECTION_TENOR)
+					continue
+				}
+			}
+
+			current.add(line, page.Page, marginNumber)
+		}
+	}
+
+	next(SECTION_RUBRUM)
+
+	return sections
+}
+
+func heading(line string) (SectionType, bool) {
+	if len(line) > MAX_HEADING_LENGTH {
+		return "", false
+	}
+
+	key := strings.ReplaceAll(line, " ", "")
+
+	for sectionType, pattern := range headingPatterns {
+		if pattern.MatchString(key) {
+			return sectionType, true
+		}
+	}
+
+	return "", false
+}
+
+// Returns the types of all sections on the given page in order of their appearance
+func PageSectionTypes(sections []Section, page int) []SectionType {
+	var types []SectionType
+	seen := map[SectionType]bool{}
+
+	for _, section := range sections {
+		if section.StartPage > page || section.EndPage < page || seen[section.Type] {
+			continue
+		}
+
+		seen[section.Type] = true
+		types = append(types, section.Type)
+	}
+
+	return types
+}
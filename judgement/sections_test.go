@@ -0,0 +1,105 @@
+package judgement
+
+import (
+	"testing"
+
+	"github.com/JuliusMoehring/court-judgment-finder-crawler/pdf"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Parse(t *testing.T) {
+	t.Run("Splits a judgement into its sections", func(t *testing.T) {
+		pages := pdf.Normalize([]string{
+			"BUNDESGERICHTSHOF\n\nIM NAMEN DES VOLKES\n\nURTEIL\n\nI ZR 126/18\n\nNachschlagewerk: ja\nBGHZ: nein\nBGHR: ja\n\n" +
+				"UWG § 5\n\nEine Werbung ist irreführend, wenn sie unwahre Angaben enthält.\n\n" +
+				"BGH, Urteil vom 12. März 2020 - I ZR 126/18 - OLG Köln\n\n" +
+				"Der I. Zivilsenat des Bundesgerichtshofs hat auf die mündliche Verhandlung vom 6. Februar 2020 für Recht erkannt:\n\n" +
+				"Die Revision der Beklagten wird zurückgewiesen.\n\nVon Rechts wegen\n",
+			"T a t b e s t a n d :\n\n1\n\nDie Klägerin ist ein Verband.\n\n2\n\nDie Beklagte vertreibt Geräte.\n\n" +
+				"Entscheidungsgründe:\n\n3\n\nDie Revision ist unbegründet.\n",
+			"4\n\nDie Werbung ist irreführend.\n",
+		})
+
+		sections := Parse(pages)
+
+		assert.Equal(t, []SectionType{SECTION_RUBRUM, SECTION_LEITSATZ, SECTION_RUBRUM, SECTION_TENOR, SECTION_TATBESTAND, SECTION_GRUENDE}, sectionTypes(sections), "Should return all sections in order")
+
+		assert.Equal(t, "UWG § 5\n\nEine Werbung ist irreführend, wenn sie unwahre Angaben enthält.", sections[1].Text, "Should return the Leitsatz")
+		assert.Equal(t, "Die Revision der Beklagten wird zurückgewiesen.\n\nVon Rechts wegen", sections[3].Text, "Should return the Tenor")
+
+		assert.Equal(t, 2, sections[4].StartPage, "Should return the first page of the Tatbestand")
+		assert.Equal(t, 1, sections[4].StartMarginNumber, "Should return the first Randnummer of the Tatbestand")
+		assert.Equal(t, 2, sections[4].EndMarginNumber, "Should return the last Randnummer of the Tatbestand")
+
+		assert.Equal(t, 2, sections[5].StartPage, "Should return the first page of the Entscheidungsgründe")
+		assert.Equal(t, 3, sections[5].EndPage, "Should return the last page of the Entscheidungsgründe")
+		assert.Equal(t, 3, sections[5].StartMarginNumber, "Should return the first Randnummer of the Entscheidungsgründe")
+		assert.Equal(t, 4, sections[5].EndMarginNumber, "Should return the last Randnummer of the Entscheidungsgründe")
+	})
+
+	t.Run("Splits a decision with Gründe", func(t *testing.T) {
+		pages := pdf.Normalize([]string{
+			"BUNDESGERICHTSHOF\n\nBESCHLUSS\n\nDer 3. Strafsenat hat am 4. Mai 2021 beschlossen:\n\n" +
+				"Die Revision wird verworfen.\n\nGründe:\n\n1\n\nDas Landgericht hat den Angeklagten verurteilt.\n",
+		})
+
+		sections := Parse(pages)
+
+		assert.Equal(t, []SectionType{SECTION_RUBRUM, SECTION_TENOR, SECTION_GRUENDE}, sectionTypes(sections), "Should return all sections in order")
+		assert.Equal(t, "Das Landgericht hat den Angeklagten verurteilt.", sections[2].Text, "Should return the Gründe without the heading")
+	})
+
+	t.Run("Does not return a Leitsatz for decisions without one", func(t *testing.T) {
+		pages := pdf.Normalize([]string{
+			"BUNDESGERICHTSHOF\n\nURTEIL\n\nNachschlagewerk: nein\nBGHZ: nein\nBGHR: nein\n\n" +
+				"Der I. Zivilsenat des Bundesgerichtshofs hat für Recht erkannt:\n\nDie Revision wird zurückgewiesen.\n",
+		})
+
+		sections := Parse(pages)
+
+		assert.Equal(t, []SectionType{SECTION_RUBRUM, SECTION_TENOR}, sectionTypes(sections), "Should return the Rubrum and the Tenor")
+		assert.Equal(t, "Die Revision wird zurückgewiesen.", sections[1].Text, "Should return the Tenor")
+	})
+
+	t.Run("Ends a Leitsatz without citation at the Tenor", func(t *testing.T) {
+		pages := pdf.Normalize([]string{
+			"BUNDESGERICHTSHOF\n\nURTEIL\n\nBGHR: ja\n\nUWG § 5\n\nEine Werbung ist irreführend.\n\n" +
+				"Der I. Zivilsenat des Bundesgerichtshofs hat für Recht erkannt:\n\nDie Revision wird zurückgewiesen.\n",
+		})
+
+		sections := Parse(pages)
+
+		assert.Equal(t, []SectionType{SECTION_RUBRUM, SECTION_LEITSATZ, SECTION_RUBRUM, SECTION_TENOR}, sectionTypes(sections), "Should return all sections in order")
+		assert.Equal(t, "UWG § 5\n\nEine Werbung ist irreführend.", sections[1].Text, "Should return the Leitsatz")
+		assert.Equal(t, "Die Revision wird zurückgewiesen.", sections[3].Text, "Should return the Tenor")
+	})
+
+	t.Run("Does not mistake sentences for headings", func(t *testing.T) {
+		pages := pdf.Normalize([]string{"Gründe für die Entscheidung sind nicht ersichtlich.\n"})
+
+		assert.Equal(t, []SectionType{SECTION_RUBRUM}, sectionTypes(Parse(pages)), "Should return a single Rubrum")
+	})
+}
+
+func Test_PageSectionTypes(t *testing.T) {
+	sections := []Section{
+		{Type: SECTION_RUBRUM, StartPage: 1, EndPage: 1},
+		{Type: SECTION_LEITSATZ, StartPage: 1, EndPage: 1},
+		{Type: SECTION_RUBRUM, StartPage: 1, EndPage: 2},
+		{Type: SECTION_TENOR, StartPage: 2, EndPage: 2},
+	}
+
+	assert.Equal(t, []SectionType{SECTION_RUBRUM, SECTION_LEITSATZ}, PageSectionTypes(sections, 1), "Should return every type once")
+	assert.Equal(t, []SectionType{SECTION_RUBRUM, SECTION_TENOR}, PageSectionTypes(sections, 2), "Should return sections spanning the page")
+	assert.Empty(t, PageSectionTypes(sections, 3), "Should return no sections for unknown pages")
+}
+
+func sectionTypes(sections []Section) []SectionType {
+	types := make([]SectionType, len(sections))
+
+	for i, section := range sections {
+		types[i] = section.Type
+	}
+
+	return types
+}
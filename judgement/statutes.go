@@ -0,0 +1,309 @@
+package judgement
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/JuliusMoehring/court-judgment-finder-crawler/pdf"
+)
+
+const (
+	STATUTE_KIND_SECTION = "§"
+	STATUTE_KIND_ARTICLE = "Art."
+)
+
+// Ranges like "§§ 305 bis 310 BGB" are expanded into every section, larger ranges only into their bounds
+const MAX_STATUTE_RANGE = 20
+
+// Abbreviations of laws have at least two capital letters, e.g. "BGB", "ZPO", "PatG" or "MarkenG"
+const lawAbbreviation = `[A-ZÄÖÜ][A-Za-zÄÖÜäöü]*[A-Z][A-Za-z]*`
+
+var (
+	statuteMarkerPattern  = regexp.MustCompile(`(§§?|Artt?\.)`)
+	statuteSectionPattern = regexp.MustCompile(`^\s*(\d+[a-z]?)\b`)
+	// Subdivisions of a section, Halbsatz and Buchstabe are skipped
+	statuteSubdivisionPattern = regexp.MustCompile(`^\s*(Abs\.|Absatz|Satz|S\.|Nr\.|Nummer|Halbsatz|Hs\.|Buchst\.|lit\.)\s*(\d+|[a-z]\b)`)
+	statuteListPattern        = regexp.MustCompile(`^\s*(?:,|und\b|sowie\b|oder\b)\s*`)
+	statuteRangePattern       = regexp.MustCompile(`^\s*(?:bis\b|-|–)\s*`)
+	statuteFollowingPattern   = regexp.MustCompile(`^\s*ff?\.`)
+	statuteLawPattern         = regexp.MustCompile(`^\s+(` + lawAbbreviation + `)\b`)
+	// Law in front of the section, e.g. "UWG § 5 Abs. 1" in the header of a decision
+	statuteLawBeforePattern = regexp.MustCompile(`(?:^|\s)(` + lawAbbreviation + `)\s+$`)
+	statuteChainPattern     = regexp.MustCompile(`^\s*(?:i\.\s?V\.\s?m\.|in Verbindung mit)\s*(?:§|Art)`)
+)
+
+// Normalized reference to a statute, e.g. "§ 5 Abs. 1 Satz 2 UWG"
+type StatuteReference struct {
+	Law string
+	// Either STATUTE_KIND_SECTION or STATUTE_KIND_ARTICLE
+	Kind    string
+	Section string
+	// Absatz, Satz and Nummer, zero if not referenced
+	Paragraph int
+	Sentence  int
+	Number    int
+	// Page of the judgement the reference was found on, zero if not extracted from a judgement
+	Page int
+}
+
+// Returns the normalized reference, e.g. "§ 5 Abs. 1 Satz 2 Nr. 1 UWG"
+func (r StatuteReference) String() string {
+	var reference strings.Builder
+
+	fmt.Fprintf(&reference, "%s %s", r.Kind, r.Section)
+
+	if r.Paragraph > 0 {
+		fmt.Fprintf(&reference, " Abs. %d", r.Paragraph)
+	}
+
+	if r.Sentence > 0 {
+		fmt.Fprintf(&reference, " Satz %d", r.Sentence)
+	}
+
+	if r.Number > 0 {
+		fmt.Fprintf(&reference, " Nr. %d", r.Number)
+	}
+
+	fmt.Fprintf(&reference, " %s", r.Law)
+
+	return reference.String()
+}
+
+func (r StatuteReference) hasSubdivision() bool {
+	return r.Paragraph > 0 || r.Sentence > 0 || r.Number > 0
+}
+
+// Returns a copy of the reference with its most specific subdivision replaced
+func (r StatuteReference) withLastSubdivision(value int) StatuteReference {
+	switch {
+	case r.Number > 0:
+		r.Number = value
+	case r.Sentence > 0:
+		r.Sentence = value
+	default:
+		r.Paragraph = value
+	}
+
+	return r
+}
+
+// Returns the referenced section without its subdivisions, e.g. "§ 5 UWG"
+func (r StatuteReference) Norm() string {
+	return fmt.Sprintf("%s %s %s", r.Kind, r.Section, r.Law)
+}
+
+type statuteGroup struct {
+	law        string
+	references []StatuteReference
+	// Whether the group is followed by "i.V.m." and another group
+	chained bool
+}
+
+// Parses all statute references in the text. References without a law, e.g. "§ 5 Abs. 1" referring to a law
+// mentioned earlier, are skipped unless they are chained to a reference with a law by "i.V.m.".
+func ParseStatuteReferences(text string) []StatuteReference {
+	var groups []statuteGroup
+
+	end := 0
+
+	for _, marker := range statuteMarkerPattern.FindAllStringSubmatchIndex(text, -1) {
+		if marker[0] < end {
+			continue
+		}
+
+		symbol := text[marker[2]:marker[3]]
+
+		kind := STATUTE_KIND_SECTION
+		if strings.HasPrefix(symbol, "Art") {
+			kind = STATUTE_KIND_ARTICLE
+		}
+
+		// "§§" and "Artt." introduce multiple sections, otherwise a list continues the subdivisions
+		multiple := symbol == "§§" || symbol == "Artt."
+
+		group, length := parseStatuteGroup(kind, multiple, text[marker[1]:])
+		if len(group.references) == 0 {
+			continue
+		}
+
+		end = marker[1] + length
+
+		if group.law == "" {
+			if match := statuteLawBeforePattern.FindStringSubmatch(text[:marker[0]]); match != nil {
+				group.law = match[1]
+			}
+		}
+
+		group.chained = statuteChainPattern.MatchString(text[end:])
+		groups = append(groups, group)
+	}
+
+	// "§ 823 Abs. 1 i.V.m. § 1004 BGB" refers to two sections of the BGB
+	for i := len(groups) - 2; i >= 0; i-- {
+		if groups[i].law == "" && groups[i].chained {
+			groups[i].law = groups[i+1].law
+		}
+	}
+
+	var references []StatuteReference
+
+	for _, group := range groups {
+		if group.law == "" {
+			continue
+		}
+
+		for _, reference := range group.references {
+			reference.Law = group.law
+			references = append(references, reference)
+		}
+	}
+
+	return references
+}
+
+// Parses the sections following a "§" or "Art." marker and returns them with the number of consumed bytes
+func parseStatuteGroup(kind string, multiple bool, text string) (statuteGroup, int) {
+	group := statuteGroup{}
+	position := 0
+
+	for {
+		reference, length, ok := parseStatuteReference(kind, text[position:])
+		if !ok {
+			break
+		}
+
+		position += length
+		group.references = append(group.references, reference)
+
+		if match := statuteFollowingPattern.FindString(text[position:]); match != "" {
+			position += len(match)
+		}
+
+		if match := statuteRangePattern.FindString(text[position:]); match != "" {
+			last, length, ok := parseStatuteReference(kind, text[position+len(match):])
+			if !ok {
+				break
+			}
+
+			position += len(match) + length
+			group.references = append(group.references, expandStatuteRange(reference, last)...)
+		}
+
+		match := statuteListPattern.FindString(text[position:])
+		if match == "" || !statuteSectionPattern.MatchString(text[position+len(match):]) {
+			break
+		}
+
+		if multiple || !reference.hasSubdivision() {
+			position += len(match)
+			continue
+		}
+
+		// "§ 5 Abs. 1 und 2 UWG" references the second Absatz of the same section
+		for match != "" {
+			value := statuteSectionPattern.FindStringSubmatch(text[position+len(match):])
+			if value == nil {
+				break
+			}
+
+			subdivision, err := strconv.Atoi(value[1])
+			if err != nil {
+				break
+			}
+
+			position += len(match) + len(value[0])
+			reference = reference.withLastSubdivision(subdivision)
+			group.references = append(group.references, reference)
+
+			match = statuteListPattern.FindString(text[position:])
+		}
+
+		break
+	}
+
+	if match := statuteLawPattern.FindStringSubmatchIndex(text[position:]); match != nil {
+		group.law = text[position+match[2] : position+match[3]]
+		position += match[1]
+	}
+
+	return group, position
+}
+
+func parseStatuteReference(kind string, text string) (StatuteReference, int, bool) {
+	match := statuteSectionPattern.FindStringSubmatch(text)
+	if match == nil {
+		return StatuteReference{}, 0, false
+	}
+
+	reference := StatuteReference{Kind: kind, Section: match[1]}
+	position := len(match[0])
+
+	for {
+		match := statuteSubdivisionPattern.FindStringSubmatch(text[position:])
+		if match == nil {
+			break
+		}
+
+		position += len(match[0])
+
+		value, err := strconv.Atoi(match[2])
+		if err != nil {
+			continue
+		}
+
+		switch match[1] {
+		case "Abs.", "Absatz":
+			reference.Paragraph = value
+		case "Satz", "S.":
+			reference.Sentence = value
+		case "Nr.", "Nummer":
+			reference.Number = value
+		}
+	}
+
+	return reference, position, true
+}
+
+// Returns the sections after first up to and including last
+func expandStatuteRange(first StatuteReference, last StatuteReference) []StatuteReference {
+	from, err := strconv.Atoi(first.Section)
+	if err != nil {
+		return []StatuteReference{last}
+	}
+
+	to, err := strconv.Atoi(last.Section)
+	if err != nil || to <= from || to-from > MAX_STATUTE_RANGE {
+		return []StatuteReference{last}
+	}
+
+	var references []StatuteReference
+
+	for section := from + 1; section < to; section++ {
+		references = append(references, StatuteReference{Kind: first.Kind, Section: strconv.Itoa(section)})
+	}
+
+	return append(references, last)
+}
+
+// Returns the statute references of all pages, every reference is only returned once per page
+func ExtractStatuteReferences(pages []pdf.NormalizedPage) []StatuteReference {
+	var references []StatuteReference
+
+	for _, page := range pages {
+		seen := map[string]bool{}
+
+		for _, reference := range ParseStatuteReferences(page.Text) {
+			if seen[reference.String()] {
+				continue
+			}
+
+			seen[reference.String()] = true
+			reference.Page = page.Page
+			references = append(references, reference)
+		}
+	}
+
+	return references
+}
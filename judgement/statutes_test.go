@@ -0,0 +1,56 @@
+package judgement
+
+import (
+	"testing"
+
+	"github.com/JuliusMoehring/court-judgment-finder-crawler/pdf"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ParseStatuteReferences(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected []string
+	}{
+		{"Parses a section with subdivisions", "nach § 5 Abs. 1 Satz 2 Nr. 1 UWG ist", []string{"§ 5 Abs. 1 Satz 2 Nr. 1 UWG"}},
+		{"Parses abbreviated subdivisions", "gemäß § 139 Absatz 1 S. 1 PatG", []string{"§ 139 Abs. 1 Satz 1 PatG"}},
+		{"Parses articles", "aus Art. 5 Abs. 1 GG folgt", []string{"Art. 5 Abs. 1 GG"}},
+		{"Parses lists of sections", "nach §§ 823, 1004 BGB", []string{"§ 823 BGB", "§ 1004 BGB"}},
+		{"Parses lists of subdivisions", "nach § 5 Abs. 1 und 2 UWG", []string{"§ 5 Abs. 1 UWG", "§ 5 Abs. 2 UWG"}},
+		{"Expands ranges", "die §§ 305 bis 307 BGB", []string{"§ 305 BGB", "§ 306 BGB", "§ 307 BGB"}},
+		{"Keeps only the bounds of large ranges", "die §§ 1 - 100 ZPO", []string{"§ 1 ZPO", "§ 100 ZPO"}},
+		{"Resolves the law of i.V.m. chains", "aus § 823 Abs. 1 i.V.m. § 1004 BGB", []string{"§ 823 Abs. 1 BGB", "§ 1004 BGB"}},
+		{"Parses the law in front of the section", "MarkenG § 14 Abs. 2 Nr. 1", []string{"§ 14 Abs. 2 Nr. 1 MarkenG"}},
+		{"Parses sections with letters", "nach § 5a Abs. 2 UWG", []string{"§ 5a Abs. 2 UWG"}},
+		{"Skips references without a law", "nach § 5 Abs. 1 ist die Klage begründet", nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var actual []string
+
+			for _, reference := range ParseStatuteReferences(test.text) {
+				actual = append(actual, reference.String())
+			}
+
+			assert.Equal(t, test.expected, actual, "Should return the normalized references")
+		})
+	}
+}
+
+func Test_StatuteReference_Norm(t *testing.T) {
+	reference := StatuteReference{Law: "UWG", Kind: STATUTE_KIND_SECTION, Section: "5", Paragraph: 1}
+
+	assert.Equal(t, "§ 5 UWG", reference.Norm(), "Should return the section without subdivisions")
+}
+
+func Test_ExtractStatuteReferences(t *testing.T) {
+	references := ExtractStatuteReferences([]pdf.NormalizedPage{
+		{Page: 1, Text: "§ 5 UWG und nochmals § 5 UWG"},
+		{Page: 2, Text: "§ 5 UWG"},
+	})
+
+	assert.Len(t, references, 2, "Should return every reference once per page")
+	assert.Equal(t, 2, references[1].Page, "Should return the page of the reference")
+}
@@ -4,47 +4,112 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"os"
 	"sync"
 
 	"github.com/JuliusMoehring/court-judgment-finder-crawler/bgh"
 	"github.com/JuliusMoehring/court-judgment-finder-crawler/download"
-	"github.com/JuliusMoehring/court-judgment-finder-crawler/embedder"
-	filestorage "github.com/JuliusMoehring/court-judgment-finder-crawler/file-storage"
 	"github.com/JuliusMoehring/court-judgment-finder-crawler/logger"
-	"github.com/JuliusMoehring/court-judgment-finder-crawler/pdf"
 	vectorstore "github.com/JuliusMoehring/court-judgment-finder-crawler/vector-store"
 	"github.com/joho/godotenv"
 )
 
 const WORKERS = 10
 
-func init() {
+func main() {
+	// Loaded here instead of in init, so the tests of the package run without a .env file
 	if err := godotenv.Load(); err != nil {
 		log.Fatal("Error loading .env file")
 	}
-}
 
-func main() {
 	ctx := context.Background()
 
-	// Initialize services
 	logger := logger.NewStdOutLogger()
+
+	command := "crawl"
+	var args []string
+
+	if len(os.Args) > 1 {
+		command = os.Args[1]
+		args = os.Args[2:]
+	}
+
+	var err error
+
+	switch command {
+	case "crawl":
+		err = runCrawl(ctx, logger)
+	case "migrate-storage":
+		err = runMigrateStorage(ctx, logger, args)
+	case "reconcile":
+		err = runReconcile(ctx, logger, args)
+	case "citations":
+		err = runCitations(ctx, logger, args)
+	case "statute":
+		err = runStatute(ctx, logger, args)
+	case "reembed":
+		err = runReembed(ctx, logger, args)
+	case "estimate":
+		err = runEstimate(ctx, logger, args)
+	case "evict-embedding-cache":
+		err = runEvictEmbeddingCache(ctx, logger, args)
+	default:
+		err = fmt.Errorf("unknown command: '%s'", command)
+	}
+
+	if err != nil {
+		logger.Fatalf("main", "%s failed: %s", command, err)
+	}
+}
+
+// Crawls the BGH website and processes every found document
+func runCrawl(ctx context.Context, logger logger.Logger) error {
+	// Initialize services
 	downloader := download.NewSimpleDownloader(logger)
-	fileStorage := filestorage.NewS3FileStorage(ctx, logger, "court-judgement-finder")
-	pdfReader := pdf.NewPopperPDFReader()
-	embedder := embedder.NewOpenAIEmbedder()
+	fileStorage, err := newFileStorage(ctx, logger, "s3")
+	if err != nil {
+		return err
+	}
+	runner, err := newCommandRunner()
+	if err != nil {
+		return err
+	}
+	pdfReader, err := newPDFReader(runner)
+	if err != nil {
+		return err
+	}
+	documentTimeout, err := newDocumentTimeout()
+	if err != nil {
+		return err
+	}
+	counter, err := newTokenCounter()
+	if err != nil {
+		return err
+	}
+	chunker, err := newChunker(counter)
+	if err != nil {
+		return err
+	}
+	embedder, closeEmbedder, err := newEmbedder(ctx, logger, counter, EMBEDDER_ALL)
+	if err != nil {
+		return err
+	}
+	defer closeEmbedder()
 	vectorStore := vectorstore.NewPostgresVectorStore(ctx, logger)
 	defer vectorStore.Close()
+	if err := ensureEmbeddingModel(ctx, embedder, vectorStore); err != nil {
+		return err
+	}
 
 	// Initialize crawler
 	crawler := bgh.NewCrawler(logger)
 
 	links, err := crawler.Crawl(ctx)
 	if err != nil {
-		panic(fmt.Sprintf("could not crawl BGH: %s", err))
+		return fmt.Errorf("could not crawl BGH: %s", err)
 	}
 
-	processor := NewProcessor(logger, downloader, fileStorage, pdfReader, embedder, vectorStore)
+	processor := NewProcessor(logger, downloader, fileStorage, pdfReader, newOCRReader(logger, runner), chunker, embedder, vectorStore, documentTimeout)
 
 	downloadLinks := make(chan string, len(links))
 	errors := make(chan error)
@@ -67,9 +132,24 @@ func main() {
 
 	wg.Wait()
 
+	if processor.BudgetExceeded() {
+		logger.Warnf("main", "embedding budget exhausted, %d of %d links are left for the next run", len(downloadLinks), len(links))
+	}
+
 	close(errors)
 
+	failed := 0
+
 	for err := range errors {
 		logger.Errorf("processor", "failed processing link: '%s'", err)
+		failed++
 	}
+
+	fmt.Printf("%d links, %d failed\n", len(links), failed)
+
+	if stats, ok := embeddingCacheStats(embedder); ok {
+		fmt.Printf("embedding cache: %d hits, %d misses\n", stats.Hits, stats.Misses)
+	}
+
+	return nil
 }
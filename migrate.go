@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	filestorage "github.com/JuliusMoehring/court-judgment-finder-crawler/file-storage"
+	"github.com/JuliusMoehring/court-judgment-finder-crawler/logger"
+)
+
+// Copies every file from one file storage backend to another. Within the same backend the files are encrypted
+// again with -to-encryption-key-id, e.g. after the key was rotated.
+func runMigrateStorage(ctx context.Context, logger logger.Logger, args []string) error {
+	flags := flag.NewFlagSet("migrate-storage", flag.ExitOnError)
+
+	from := flags.String("from", "", "file storage to copy from (s3, supabase or local)")
+	to := flags.String("to", "", "file storage to copy to (s3, supabase or local)")
+	prefix := flags.String("prefix", "", "only copy files whose path starts with the prefix")
+	workers := flags.Int("workers", WORKERS, "number of files copied concurrently")
+	dryRun := flags.Bool("dry-run", false, "only report what would be copied")
+	// Both sides default to the configured key, so files can also be encrypted or decrypted while migrating
+	fromKeyID := flags.String("from-encryption-key-id", os.Getenv("FILE_STORAGE_ENCRYPTION_KEY_ID"), "id of the key the source is encrypted with, empty if it is not encrypted")
+	toKeyID := flags.String("to-encryption-key-id", os.Getenv("FILE_STORAGE_ENCRYPTION_KEY_ID"), "id of the key to encrypt the destination with, empty to not encrypt it")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *from == "" || *to == "" {
+		return fmt.Errorf("both -from and -to are required")
+	}
+
+	newStorage := newFileStorageWithKey
+
+	if *from == *to {
+		// Plain and encrypted files cannot be told apart by both sides, only rotating the key is supported
+		if *fromKeyID == "" || *toKeyID == "" || *fromKeyID == *toKeyID {
+			return fmt.Errorf("-from and -to must be different file storages unless they are encrypted with different key ids")
+		}
+
+		// The content addressed layout does not write contents that are already stored, so every object is
+		// encrypted again on its own
+		newStorage = newObjectStorageWithKey
+	}
+
+	source, err := newStorage(ctx, logger, *from, *fromKeyID)
+	if err != nil {
+		return err
+	}
+
+	destination, err := newStorage(ctx, logger, *to, *toKeyID)
+	if err != nil {
+		return err
+	}
+
+	report, err := filestorage.Migrate(ctx, logger, source, destination, filestorage.MigrateOptions{
+		Prefix:  *prefix,
+		Workers: *workers,
+		DryRun:  *dryRun,
+	})
+	if err != nil {
+		return err
+	}
+
+	if *dryRun {
+		for _, path := range report.Copied {
+			fmt.Printf("would copy: %s\n", path)
+		}
+
+		fmt.Printf("dry run: %d files would be copied, %d already migrated\n", len(report.Copied), len(report.Skipped))
+	} else {
+		fmt.Printf("copied %d files, %d already migrated, %d failed\n", len(report.Copied), len(report.Skipped), len(report.Failed))
+	}
+
+	for path, err := range report.Failed {
+		fmt.Printf("failed: %s: %s\n", path, err)
+	}
+
+	if len(report.Failed) > 0 {
+		return fmt.Errorf("%d files could not be migrated", len(report.Failed))
+	}
+
+	return nil
+}
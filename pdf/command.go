@@ -0,0 +1,171 @@
+package pdf
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+var (
+	ErrTimeout        = errors.New("pdf extraction timed out")
+	ErrCorruptPDF     = errors.New("pdf is corrupt or cannot be opened")
+	ErrMissingBinary  = errors.New("required binary not found in PATH")
+	ErrOutputTooLarge = errors.New("pdf extraction output exceeds size limit")
+)
+
+// Poppler tools exit with this code if the PDF cannot be opened
+const POPPLER_EXIT_CODE_OPEN_ERROR = 1
+
+// Binaries whose exit codes follow poppler, other binaries like tesseract also exit with 1 on unrelated errors
+var popplerBinaries = map[string]bool{
+	"pdfinfo":   true,
+	"pdftoppm":  true,
+	"pdftotext": true,
+}
+
+// Describes why running an external binary failed, matches one of ErrTimeout, ErrCorruptPDF,
+// ErrMissingBinary or ErrOutputTooLarge with errors.Is
+type ExtractionError struct {
+	Binary string
+	Kind   error
+	Stderr string
+	Err    error
+}
+
+func (e *ExtractionError) Error() string {
+	message := fmt.Sprintf("%s: %s", e.Binary, e.Kind)
+
+	if e.Err != nil {
+		message += fmt.Sprintf(": %s", e.Err)
+	}
+
+	if e.Stderr != "" {
+		message += fmt.Sprintf(" (%s)", e.Stderr)
+	}
+
+	return message
+}
+
+func (e *ExtractionError) Unwrap() []error {
+	return []error{e.Kind, e.Err}
+}
+
+type CommandOptions struct {
+	// Maximum duration of a single command, zero means no timeout
+	Timeout time.Duration
+	// Maximum number of commands running at the same time, zero means no limit
+	MaxConcurrency int
+	// Maximum number of bytes a command may write to stdout, zero means no limit
+	MaxOutputSize int
+}
+
+// Runs the external binaries used for extraction with timeouts, a limit on concurrently running
+// processes and a cap on their output, independent of how many workers are processing documents.
+type CommandRunner struct {
+	options   CommandOptions
+	semaphore chan struct{}
+}
+
+func NewCommandRunner(options CommandOptions) *CommandRunner {
+	runner := &CommandRunner{
+		options: options,
+	}
+
+	if options.MaxConcurrency > 0 {
+		runner.semaphore = make(chan struct{}, options.MaxConcurrency)
+	}
+
+	return runner
+}
+
+// Returns an ErrMissingBinary error if the binary cannot be found
+func LookPath(binary string) error {
+	if _, err := exec.LookPath(binary); err != nil {
+		return &ExtractionError{Binary: binary, Kind: ErrMissingBinary, Err: err}
+	}
+
+	return nil
+}
+
+// Not embedding bytes.Buffer on purpose, its ReadFrom would be used by io.Copy and bypass the limit
+type limitedBuffer struct {
+	buffer bytes.Buffer
+
+	limit    int
+	exceeded bool
+	// Stops the process as soon as the limit is exceeded
+	cancel context.CancelFunc
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if b.limit > 0 && b.buffer.Len()+len(p) > b.limit {
+		b.exceeded = true
+		b.cancel()
+		return 0, ErrOutputTooLarge
+	}
+
+	return b.buffer.Write(p)
+}
+
+// Runs the binary with the given arguments and stdin and returns its stdout
+func (r *CommandRunner) Run(ctx context.Context, stdin io.Reader, binary string, args ...string) ([]byte, error) {
+	if r.semaphore != nil {
+		select {
+		case r.semaphore <- struct{}{}:
+			defer func() { <-r.semaphore }()
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	if r.options.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.options.Timeout)
+		defer cancel()
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stdout := &limitedBuffer{limit: r.options.MaxOutputSize, cancel: cancel}
+	var stderr bytes.Buffer
+
+	command := exec.CommandContext(ctx, binary, args...)
+	command.Stdin = stdin
+	command.Stdout = stdout
+	command.Stderr = &stderr
+
+	err := command.Run()
+	if err == nil {
+		return stdout.buffer.Bytes(), nil
+	}
+
+	extractionError := &ExtractionError{
+		Binary: binary,
+		Stderr: strings.TrimSpace(stderr.String()),
+		Err:    err,
+	}
+
+	var exitError *exec.ExitError
+
+	switch {
+	case errors.Is(err, exec.ErrNotFound):
+		extractionError.Kind = ErrMissingBinary
+	case stdout.exceeded:
+		extractionError.Kind = ErrOutputTooLarge
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		extractionError.Kind = ErrTimeout
+	case popplerBinaries[filepath.Base(binary)] && errors.As(err, &exitError) && exitError.ExitCode() == POPPLER_EXIT_CODE_OPEN_ERROR:
+		extractionError.Kind = ErrCorruptPDF
+	default:
+		return nil, fmt.Errorf("%s failed: %w (%s)", binary, err, extractionError.Stderr)
+	}
+
+	return nil, extractionError
+}
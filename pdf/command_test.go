@@ -0,0 +1,126 @@
+package pdf
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_CommandRunner(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Returns the output of the command", func(t *testing.T) {
+		runner := NewCommandRunner(CommandOptions{})
+
+		output, err := runner.Run(ctx, nil, "echo", "Urteil")
+
+		assert.NoError(t, err, "Should not return an error")
+		assert.Equal(t, "Urteil\n", string(output), "Should return stdout")
+	})
+
+	t.Run("Returns `ErrTimeout` if the command takes too long", func(t *testing.T) {
+		runner := NewCommandRunner(CommandOptions{Timeout: 50 * time.Millisecond})
+
+		start := time.Now()
+		_, err := runner.Run(ctx, nil, "sleep", "5")
+
+		assert.ErrorIs(t, err, ErrTimeout, "Should return an `ErrTimeout` error")
+		assert.Less(t, time.Since(start), 2*time.Second, "Should kill the command")
+	})
+
+	t.Run("Returns `ErrOutputTooLarge` if the output exceeds the limit", func(t *testing.T) {
+		runner := NewCommandRunner(CommandOptions{MaxOutputSize: 1024})
+
+		_, err := runner.Run(ctx, nil, "yes")
+
+		assert.ErrorIs(t, err, ErrOutputTooLarge, "Should return an `ErrOutputTooLarge` error")
+	})
+
+	t.Run("Returns `ErrMissingBinary` if the binary does not exist", func(t *testing.T) {
+		runner := NewCommandRunner(CommandOptions{})
+
+		_, err := runner.Run(ctx, nil, "pdftotext-does-not-exist")
+
+		assert.ErrorIs(t, err, ErrMissingBinary, "Should return an `ErrMissingBinary` error")
+		assert.ErrorIs(t, LookPath("pdftotext-does-not-exist"), ErrMissingBinary, "Should return an `ErrMissingBinary` error")
+	})
+
+	t.Run("Returns `ErrCorruptPDF` if a poppler command cannot open the file", func(t *testing.T) {
+		runner := NewCommandRunner(CommandOptions{})
+
+		// Stands in for pdfinfo failing to open the file
+		binary := filepath.Join(t.TempDir(), "pdfinfo")
+		assert.NoError(t, os.WriteFile(binary, []byte("#!/bin/sh\nexit 1\n"), 0o755), "Should write the script")
+
+		_, err := runner.Run(ctx, nil, binary)
+
+		var extractionError *ExtractionError
+		assert.True(t, errors.As(err, &extractionError), "Should return an `ExtractionError`")
+		assert.ErrorIs(t, err, ErrCorruptPDF, "Should return an `ErrCorruptPDF` error")
+	})
+
+	t.Run("Does not return `ErrCorruptPDF` if other commands exit with 1", func(t *testing.T) {
+		runner := NewCommandRunner(CommandOptions{})
+
+		_, err := runner.Run(ctx, nil, "false")
+
+		assert.Error(t, err, "Should return an error")
+		assert.NotErrorIs(t, err, ErrCorruptPDF, "Should not return an `ErrCorruptPDF` error")
+	})
+
+	t.Run("Limits the number of concurrently running commands", func(t *testing.T) {
+		runner := NewCommandRunner(CommandOptions{MaxConcurrency: 2})
+
+		var maxRunning atomic.Int32
+		var wg sync.WaitGroup
+
+		for i := 0; i < 6; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				_, err := runner.Run(ctx, nil, "sleep", "0.1")
+				assert.NoError(t, err, "Should not return an error")
+			}()
+		}
+
+		done := make(chan struct{})
+		go func() {
+			wg.Wait()
+			close(done)
+		}()
+
+		for {
+			select {
+			case <-done:
+				assert.Equal(t, int32(2), maxRunning.Load(), "Should run at most two commands at once")
+				return
+			case <-time.After(time.Millisecond):
+				// Every running command holds a slot of the semaphore
+				if running := int32(len(runner.semaphore)); running > maxRunning.Load() {
+					maxRunning.Store(running)
+				}
+			}
+		}
+	})
+
+	t.Run("Returns the context error while waiting for a free slot", func(t *testing.T) {
+		runner := NewCommandRunner(CommandOptions{MaxConcurrency: 1})
+		runner.semaphore <- struct{}{}
+		defer func() { <-runner.semaphore }()
+
+		ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		defer cancel()
+
+		_, err := runner.Run(ctx, nil, "echo")
+
+		assert.ErrorIs(t, err, context.DeadlineExceeded, "Should return the context error")
+	})
+}
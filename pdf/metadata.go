@@ -0,0 +1,115 @@
+package pdf
+
+import (
+	"bufio"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Metadata struct {
+	Pages      int        `json:"pages"`
+	Title      string     `json:"title,omitempty"`
+	Producer   string     `json:"producer,omitempty"`
+	CreatedAt  *time.Time `json:"createdAt,omitempty"`
+	ModifiedAt *time.Time `json:"modifiedAt,omitempty"`
+	Encrypted  bool       `json:"encrypted"`
+	FileSize   int        `json:"fileSize"`
+}
+
+// Returns an error if the number of extracted pages does not match the page count of the PDF
+func (m *Metadata) CheckPageCount(extractedPages int) error {
+	if m.Pages != extractedPages {
+		return fmt.Errorf("%w: pdf has %d pages, extracted %d", ErrPageCountMismatch, m.Pages, extractedPages)
+	}
+
+	return nil
+}
+
+// Parses the output of "pdfinfo -isodates"
+func parsePDFInfo(output string) (*Metadata, error) {
+	metadata := &Metadata{}
+
+	scanner := bufio.NewScanner(strings.NewReader(output))
+
+	for scanner.Scan() {
+		key, value, found := strings.Cut(scanner.Text(), ":")
+		if !found {
+			continue
+		}
+
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "Title":
+			metadata.Title = value
+		case "Producer":
+			metadata.Producer = value
+		case "CreationDate":
+			metadata.CreatedAt = parseISODate(value)
+		case "ModDate":
+			metadata.ModifiedAt = parseISODate(value)
+		case "Encrypted":
+			metadata.Encrypted = strings.HasPrefix(value, "yes")
+		case "Pages":
+			pages, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid page count '%s': %w", value, err)
+			}
+
+			metadata.Pages = pages
+		}
+	}
+
+	return metadata, scanner.Err()
+}
+
+func parseISODate(value string) *time.Time {
+	date, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil
+	}
+
+	return &date
+}
+
+var pdfDatePattern = regexp.MustCompile(`^(?:D:)?(\d{4})(\d{2})?(\d{2})?(\d{2})?(\d{2})?(\d{2})?(Z|[+-]\d{2}'?\d{2}'?)?`)
+
+// Parses dates in the PDF format "D:YYYYMMDDHHmmSSOHH'mm'", where everything after the year is optional
+func parsePDFDate(value string) *time.Time {
+	match := pdfDatePattern.FindStringSubmatch(strings.TrimSpace(value))
+	if match == nil {
+		return nil
+	}
+
+	parts := make([]int, 6)
+	defaults := []int{0, 1, 1, 0, 0, 0}
+
+	for i := range parts {
+		parts[i] = defaults[i]
+
+		if match[i+1] != "" {
+			parts[i], _ = strconv.Atoi(match[i+1])
+		}
+	}
+
+	location := time.UTC
+
+	if zone := strings.ReplaceAll(match[7], "'", ""); zone != "" && zone != "Z" {
+		hours, _ := strconv.Atoi(zone[1:3])
+		minutes, _ := strconv.Atoi(zone[3:])
+
+		offset := hours*60*60 + minutes*60
+		if zone[0] == '-' {
+			offset = -offset
+		}
+
+		location = time.FixedZone(zone, offset)
+	}
+
+	date := time.Date(parts[0], time.Month(parts[1]), parts[2], parts[3], parts[4], parts[5], 0, location)
+
+	return &date
+}
@@ -0,0 +1,73 @@
+package pdf
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_parsePDFInfo(t *testing.T) {
+	t.Run("Parses the output of pdfinfo", func(t *testing.T) {
+		metadata, err := parsePDFInfo(`Title:           Urteil I ZR 126/18
+Producer:        fixture
+CreationDate:    2020-03-12T10:00:00Z
+ModDate:         2020-03-13T11:00:00+01:00
+Encrypted:       no
+Pages:           2
+File size:       1239 bytes
+`)
+
+		assert.NoError(t, err, "Should not return an error")
+		assert.Equal(t, 2, metadata.Pages, "Should return the page count")
+		assert.Equal(t, "Urteil I ZR 126/18", metadata.Title, "Should return the title")
+		assert.Equal(t, "fixture", metadata.Producer, "Should return the producer")
+		assert.False(t, metadata.Encrypted, "Should not be encrypted")
+		assert.True(t, metadata.CreatedAt.Equal(time.Date(2020, 3, 12, 10, 0, 0, 0, time.UTC)), "Should return the creation date")
+		assert.True(t, metadata.ModifiedAt.Equal(time.Date(2020, 3, 13, 10, 0, 0, 0, time.UTC)), "Should return the modification date")
+	})
+
+	t.Run("Returns an error for an invalid page count", func(t *testing.T) {
+		_, err := parsePDFInfo("Pages: many\n")
+
+		assert.Error(t, err, "Should return an error")
+	})
+}
+
+func Test_parsePDFDate(t *testing.T) {
+	t.Run("Parses dates with time zone offsets", func(t *testing.T) {
+		date := parsePDFDate("D:20200312110000+01'00'")
+
+		assert.True(t, date.Equal(time.Date(2020, 3, 12, 10, 0, 0, 0, time.UTC)), "Should return the correct date")
+	})
+
+	t.Run("Parses dates without time", func(t *testing.T) {
+		date := parsePDFDate("D:2020")
+
+		assert.True(t, date.Equal(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)), "Should default to the start of the year")
+	})
+
+	t.Run("Returns nil for invalid dates", func(t *testing.T) {
+		assert.Nil(t, parsePDFDate("yesterday"), "Should return nil")
+	})
+}
+
+func Test_PurePDFReaderMetadata(t *testing.T) {
+	t.Run("Reads the metadata of the PDF", func(t *testing.T) {
+		data, err := os.ReadFile("testdata/judgement.pdf")
+		assert.NoError(t, err, "Should read the fixture")
+
+		metadata, err := NewPurePDFReader().Metadata(context.Background(), bytes.NewReader(data))
+
+		assert.NoError(t, err, "Should not return an error")
+		assert.Equal(t, 2, metadata.Pages, "Should return the page count")
+		assert.Equal(t, "Urteil I ZR 126/18", metadata.Title, "Should return the title")
+		assert.False(t, metadata.Encrypted, "Should not be encrypted")
+		assert.True(t, metadata.CreatedAt.Equal(time.Date(2020, 3, 12, 10, 0, 0, 0, time.UTC)), "Should return the creation date")
+		assert.NoError(t, metadata.CheckPageCount(2), "Should match the extracted page count")
+		assert.ErrorIs(t, metadata.CheckPageCount(3), ErrPageCountMismatch, "Should return an `ErrPageCountMismatch` error")
+	})
+}
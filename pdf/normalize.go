@@ -0,0 +1,274 @@
+package pdf
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// Only this many lines at the top and the bottom of a page are considered headers and footers
+	HEADER_FOOTER_LINES = 3
+	// Lines at the top or bottom that appear on at least this share of pages are removed
+	HEADER_FOOTER_MIN_SHARE = 0.5
+	// Randnummern are increasing, a bigger jump is more likely a number within the text
+	MAX_MARGIN_NUMBER_STEP = 5
+)
+
+var (
+	pageNumberPattern   = regexp.MustCompile(`^(?:[-–]\s*\d{1,4}\s*[-–]|(?i:seite)\s+\d{1,4}(?:\s+(?i:von)\s+\d{1,4})?)$`)
+	marginNumberPattern = regexp.MustCompile(`^\d{1,4}$`)
+	digitsPattern       = regexp.MustCompile(`\d+`)
+	spacesPattern       = regexp.MustCompile(`[ \t\p{Zs}]+`)
+)
+
+// Words after a hyphen at the end of a line that indicate an elision like "Bundes- und Landesrecht"
+var elisionWords = map[string]bool{
+	"und":   true,
+	"oder":  true,
+	"bzw":   true,
+	"sowie": true,
+	"wie":   true,
+}
+
+// Marks the start of a paragraph with a Randnummer
+type MarginNumber struct {
+	Number int
+	// Byte offset of the paragraph in the normalized text of the page
+	Offset int
+}
+
+type NormalizedPage struct {
+	// Number of the original page, starting at 1
+	Page          int
+	Text          string
+	MarginNumbers []MarginNumber
+}
+
+// Cleans up the extracted text of all pages of a document: removes recurring headers, footers and page numbers,
+// rejoins hyphenated words, normalizes whitespace and ligatures and extracts Randnummern.
+func Normalize(pages []string) []NormalizedPage {
+	lines := make([][]string, len(pages))
+
+	for i, page := range pages {
+		lines[i] = splitLines(page)
+	}
+
+	recurring := recurringLines(lines)
+
+	normalized := make([]NormalizedPage, len(pages))
+	lastMarginNumber := 0
+
+	for i := range lines {
+		var kept []string
+
+		for j, line := range lines[i] {
+			edge := j < HEADER_FOOTER_LINES || j >= len(lines[i])-HEADER_FOOTER_LINES
+
+			if edge && (recurring[lineKey(line)] || pageNumberPattern.MatchString(line)) {
+				continue
+			}
+
+			kept = append(kept, line)
+		}
+
+		normalized[i] = buildPage(i+1, kept, &lastMarginNumber)
+	}
+
+	rejoinPageBreaks(normalized)
+
+	return normalized
+}
+
+func splitLines(page string) []string {
+	page = normalizeCharacters(page)
+
+	var lines []string
+
+	for _, line := range strings.Split(page, "\n") {
+		line = strings.TrimSpace(spacesPattern.ReplaceAllString(line, " "))
+
+		// Keep a single empty line between paragraphs
+		if line == "" && (len(lines) == 0 || lines[len(lines)-1] == "") {
+			continue
+		}
+
+		lines = append(lines, line)
+	}
+
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return lines
+}
+
+// Lines differing only in numbers, like "I ZR 126/18 - Seite 2", are considered the same line
+func lineKey(line string) string {
+	return digitsPattern.ReplaceAllString(line, "#")
+}
+
+func recurringLines(pages [][]string) map[string]bool {
+	recurring := map[string]bool{}
+
+	if len(pages) < 2 {
+		return recurring
+	}
+
+	counts := map[string]int{}
+
+	for _, lines := range pages {
+		seen := map[string]bool{}
+
+		for j, line := range lines {
+			if line == "" || (j >= HEADER_FOOTER_LINES && j < len(lines)-HEADER_FOOTER_LINES) {
+				continue
+			}
+
+			// Randnummern often start a page, but are no headers
+			if marginNumberPattern.MatchString(line) {
+				continue
+			}
+
+			key := lineKey(line)
+			if !seen[key] {
+				seen[key] = true
+				counts[key]++
+			}
+		}
+	}
+
+	for key, count := range counts {
+		if count >= 2 && float64(count) >= HEADER_FOOTER_MIN_SHARE*float64(len(pages)) {
+			recurring[key] = true
+		}
+	}
+
+	return recurring
+}
+
+func buildPage(page int, lines []string, lastMarginNumber *int) NormalizedPage {
+	normalized := NormalizedPage{
+		Page: page,
+	}
+
+	var text strings.Builder
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+
+		if marginNumberPattern.MatchString(line) {
+			number, _ := strconv.Atoi(line)
+
+			if number > *lastMarginNumber && number <= *lastMarginNumber+MAX_MARGIN_NUMBER_STEP {
+				*lastMarginNumber = number
+
+				// Randnummern start a new paragraph
+				if text.Len() > 0 && !strings.HasSuffix(text.String(), "\n\n") {
+					text.WriteString("\n\n")
+				}
+
+				normalized.MarginNumbers = append(normalized.MarginNumbers, MarginNumber{
+					Number: number,
+					Offset: text.Len(),
+				})
+
+				// Skip the empty line that usually follows the Randnummer
+				for i+1 < len(lines) && lines[i+1] == "" {
+					i++
+				}
+
+				continue
+			}
+		}
+
+		if line == "" {
+			if text.Len() > 0 && !strings.HasSuffix(text.String(), "\n\n") {
+				text.WriteString("\n\n")
+			}
+
+			continue
+		}
+
+		current := text.String()
+
+		switch {
+		case text.Len() == 0 || strings.HasSuffix(current, "\n\n"):
+		case isHyphenated(current, line):
+			// Remove the hyphen and join the word
+			trimmed := strings.TrimSuffix(current, "-")
+			text.Reset()
+			text.WriteString(trimmed)
+		default:
+			text.WriteString("\n")
+		}
+
+		text.WriteString(line)
+	}
+
+	normalized.Text = strings.TrimSpace(text.String())
+
+	return normalized
+}
+
+// Returns whether the text ends with a word that is hyphenated and continued at the start of the next line
+func isHyphenated(text string, next string) bool {
+	if !strings.HasSuffix(text, "-") {
+		return false
+	}
+
+	beforeHyphen, _ := utf8.DecodeLastRuneInString(strings.TrimSuffix(text, "-"))
+	if !unicode.IsLetter(beforeHyphen) {
+		return false
+	}
+
+	first, _ := utf8.DecodeRuneInString(next)
+	if !unicode.IsLower(first) {
+		return false
+	}
+
+	word := strings.FieldsFunc(next, func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+
+	return len(word) == 0 || !elisionWords[word[0]]
+}
+
+// Moves the rest of a word hyphenated at the end of a page to the previous page
+func rejoinPageBreaks(pages []NormalizedPage) {
+	for i := 0; i+1 < len(pages); i++ {
+		next := &pages[i+1]
+
+		if next.Text == "" || (len(next.MarginNumbers) > 0 && next.MarginNumbers[0].Offset == 0) || !isHyphenated(pages[i].Text, next.Text) {
+			continue
+		}
+
+		end := strings.IndexFunc(next.Text, unicode.IsSpace)
+		if end < 0 {
+			end = len(next.Text)
+		}
+
+		pages[i].Text = strings.TrimSuffix(pages[i].Text, "-") + next.Text[:end]
+
+		rest := strings.TrimLeftFunc(next.Text[end:], unicode.IsSpace)
+		removed := len(next.Text) - len(rest)
+		next.Text = rest
+
+		for j := range next.MarginNumbers {
+			next.MarginNumbers[j].Offset -= removed
+		}
+	}
+}
+
+// Returns the text of all normalized pages separated by form feeds
+func NormalizedText(pages []NormalizedPage) string {
+	texts := make([]string, len(pages))
+
+	for i, page := range pages {
+		texts[i] = page.Text
+	}
+
+	return strings.Join(texts, "\f")
+}
@@ -0,0 +1,67 @@
+package pdf
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Normalize(t *testing.T) {
+	t.Run("Removes recurring headers and page numbers", func(t *testing.T) {
+		pages := Normalize([]string{
+			"BUNDESGERICHTSHOF\n\nIM NAMEN DES VOLKES\n\nURTEIL\n",
+			"- 2 -\n\nDer Kläger begehrt Unterlassung.\n",
+			"- 3 -\n\nDie Revision ist begründet.\n",
+		})
+
+		assert.Equal(t, "Der Kläger begehrt Unterlassung.", pages[1].Text, "Should remove the page number")
+		assert.Equal(t, "Die Revision ist begründet.", pages[2].Text, "Should remove the page number")
+	})
+
+	t.Run("Removes headers that only differ in numbers", func(t *testing.T) {
+		pages := Normalize([]string{
+			"I ZR 126/18 Seite 1\nErster Absatz.\n",
+			"I ZR 126/18 Seite 2\nZweiter Absatz.\n",
+		})
+
+		assert.Equal(t, "Erster Absatz.", pages[0].Text, "Should remove the header")
+		assert.Equal(t, "Zweiter Absatz.", pages[1].Text, "Should remove the header")
+	})
+
+	t.Run("Rejoins hyphenated words", func(t *testing.T) {
+		pages := Normalize([]string{"Nach ständiger Rechts-\nprechung des Senats\n"})
+
+		assert.Equal(t, "Nach ständiger Rechtsprechung des Senats", pages[0].Text, "Should join the word")
+	})
+
+	t.Run("Keeps hyphens of elisions", func(t *testing.T) {
+		pages := Normalize([]string{"Regelungen des Bundes-\nund Landesrechts\n"})
+
+		assert.Equal(t, "Regelungen des Bundes-\nund Landesrechts", pages[0].Text, "Should keep the hyphen")
+	})
+
+	t.Run("Rejoins words hyphenated across pages", func(t *testing.T) {
+		pages := Normalize([]string{"Der Unterlassungs-\n", "anspruch ist begründet.\n"})
+
+		assert.Equal(t, "Der Unterlassungsanspruch", pages[0].Text, "Should move the rest of the word to the previous page")
+		assert.Equal(t, "ist begründet.", pages[1].Text, "Should remove the rest of the word")
+	})
+
+	t.Run("Normalizes whitespace and ligatures", func(t *testing.T) {
+		pages := Normalize([]string{"Die   Beklagte  ist\tverpﬂichtet.\n\n\n\nWeiter."})
+
+		assert.Equal(t, "Die Beklagte ist verpflichtet.\n\nWeiter.", pages[0].Text, "Should normalize the text")
+	})
+
+	t.Run("Extracts Randnummern", func(t *testing.T) {
+		pages := Normalize([]string{
+			"Gründe:\n\n1\n\nDer Kläger ist Patentinhaber.\n\n2\n\nDie Beklagte vertreibt Geräte.\n",
+			"3\n\nDas Berufungsgericht hat die Klage abgewiesen. Es hat 100 Geräte geprüft.\n",
+		})
+
+		assert.Equal(t, "Gründe:\n\nDer Kläger ist Patentinhaber.\n\nDie Beklagte vertreibt Geräte.", pages[0].Text, "Should remove the Randnummern from the text")
+		assert.Equal(t, []MarginNumber{{Number: 1, Offset: 10}, {Number: 2, Offset: 42}}, pages[0].MarginNumbers, "Should return the Randnummern with their offsets")
+		assert.Equal(t, []MarginNumber{{Number: 3, Offset: 0}}, pages[1].MarginNumbers, "Should continue the Randnummern on the next page")
+		assert.Equal(t, 2, pages[1].Page, "Should keep the original page number")
+	})
+}
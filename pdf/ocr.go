@@ -0,0 +1,97 @@
+package pdf
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const (
+	EXTRACTION_METHOD_TEXT = "text"
+	EXTRACTION_METHOD_OCR  = "ocr"
+
+	// Pages with fewer letters are most likely scans without a text layer
+	MIN_TEXT_LETTERS = 25
+	OCR_RESOLUTION   = 300
+	OCR_LANGUAGE     = "deu"
+)
+
+// Recognizes the text of single pages of scanned PDFs
+type OCRReader interface {
+	// Returns the text of the page with the given number, starting at 1
+	ReadPage(ctx context.Context, data []byte, page int) ([]byte, error)
+	// Identifies the OCR engine and its version, e.g. "tesseract 5.3.0"
+	Version() string
+}
+
+// Returns whether the extracted text of a page is implausibly short, so the page should be read with OCR
+func NeedsOCR(text string) bool {
+	letters := 0
+
+	for _, r := range text {
+		if unicode.IsLetter(r) {
+			letters++
+		}
+
+		if letters >= MIN_TEXT_LETTERS {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Renders pages with pdftoppm and recognizes their text with tesseract
+type TesseractOCRReader struct {
+	runner  *CommandRunner
+	version string
+}
+
+func NewTesseractOCRReader(runner *CommandRunner) (OCRReader, error) {
+	for _, binary := range []string{"pdftoppm", "tesseract"} {
+		if err := LookPath(binary); err != nil {
+			return nil, err
+		}
+	}
+
+	return &TesseractOCRReader{
+		runner:  runner,
+		version: tesseractVersion(),
+	}, nil
+}
+
+// tesseract prints its version in the first line in the format "tesseract 5.3.0"
+func tesseractVersion() string {
+	output, _ := exec.Command("tesseract", "--version").CombinedOutput()
+
+	line, _, _ := strings.Cut(string(output), "\n")
+	if strings.HasPrefix(line, "tesseract ") {
+		return strings.TrimSpace(line)
+	}
+
+	return "tesseract unknown"
+}
+
+func (t *TesseractOCRReader) ReadPage(ctx context.Context, data []byte, page int) ([]byte, error) {
+	pageNumber := strconv.Itoa(page)
+
+	image, err := t.runner.Run(ctx, bytes.NewReader(data), "pdftoppm", "-f", pageNumber, "-l", pageNumber, "-r", strconv.Itoa(OCR_RESOLUTION), "-gray", "-png", "-")
+	if err != nil {
+		return nil, fmt.Errorf("failed rendering page %d: %w", page, err)
+	}
+
+	text, err := t.runner.Run(ctx, bytes.NewReader(image), "tesseract", "stdin", "stdout", "-l", OCR_LANGUAGE)
+	if err != nil {
+		return nil, fmt.Errorf("failed recognizing text of page %d: %w", page, err)
+	}
+
+	return text, nil
+}
+
+func (t *TesseractOCRReader) Version() string {
+	return t.version
+}
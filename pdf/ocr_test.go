@@ -0,0 +1,23 @@
+package pdf
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_NeedsOCR(t *testing.T) {
+	t.Run("Returns true for empty pages", func(t *testing.T) {
+		assert.True(t, NeedsOCR(""), "Should need OCR")
+		assert.True(t, NeedsOCR(" \n\n "), "Should need OCR")
+	})
+
+	t.Run("Returns true for pages with only a page number", func(t *testing.T) {
+		assert.True(t, NeedsOCR("\n\n- 12 -\n"), "Should need OCR")
+	})
+
+	t.Run("Returns false for pages with text", func(t *testing.T) {
+		assert.False(t, NeedsOCR(strings.Repeat("Entscheidungsgründe ", 5)), "Should not need OCR")
+	})
+}
@@ -2,26 +2,56 @@ package pdf
 
 import (
 	"context"
+	"io"
 	"os/exec"
+	"strings"
 )
 
 type PopperPDFReader struct {
+	runner  *CommandRunner
+	version string
 }
 
-func NewPopperPDFReader() Reader {
-	_, err := exec.LookPath("pdftotext")
-	if err != nil {
-		panic("pdftotext not found in PATH")
+func NewPopperPDFReader(runner *CommandRunner) (Reader, error) {
+	for _, binary := range []string{"pdftotext", "pdfinfo"} {
+		if err := LookPath(binary); err != nil {
+			return nil, err
+		}
+	}
+
+	return &PopperPDFReader{
+		runner:  runner,
+		version: popperVersion(),
+	}, nil
+}
+
+// pdftotext prints its version to stderr in the format "pdftotext version 22.02.0"
+func popperVersion() string {
+	output, _ := exec.Command("pdftotext", "-v").CombinedOutput()
+
+	for _, line := range strings.Split(string(output), "\n") {
+		if version, found := strings.CutPrefix(strings.TrimSpace(line), "pdftotext version "); found {
+			return "pdftotext " + version
+		}
 	}
 
-	return &PopperPDFReader{}
+	return "pdftotext unknown"
+}
+
+// Pipes the PDF to pdftotext via stdin, so no temporary files are needed
+func (p *PopperPDFReader) Read(ctx context.Context, r io.Reader) ([]byte, error) {
+	return p.runner.Run(ctx, r, "pdftotext", "-", "-")
 }
 
-func (p *PopperPDFReader) Read(ctx context.Context, path string) ([]byte, error) {
-	bytes, err := exec.CommandContext(ctx, "pdftotext", path, "-").Output()
+func (p *PopperPDFReader) Metadata(ctx context.Context, r io.Reader) (*Metadata, error) {
+	output, err := p.runner.Run(ctx, r, "pdfinfo", "-isodates", "-")
 	if err != nil {
 		return nil, err
 	}
 
-	return bytes, nil
+	return parsePDFInfo(string(output))
+}
+
+func (p *PopperPDFReader) Version() string {
+	return p.version
 }
@@ -0,0 +1,165 @@
+package pdf
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/ledongthuc/pdf"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Increase whenever the extraction changes, so stored sidecars are extracted again
+const PURE_PDF_READER_VERSION = "pure-go 1"
+
+var ligatures = strings.NewReplacer(
+	"ﬀ", "ff",
+	"ﬁ", "fi",
+	"ﬂ", "fl",
+	"ﬃ", "ffi",
+	"ﬄ", "ffl",
+	"ﬅ", "st",
+	"ﬆ", "st",
+)
+
+// Replaces ligatures with their single characters and composes umlauts that are stored as
+// a base character followed by a combining diaeresis
+func normalizeCharacters(text string) string {
+	return norm.NFC.String(ligatures.Replace(text))
+}
+
+// Extracts the text of PDFs without any external binaries
+type PurePDFReader struct {
+}
+
+// The pdf library panics on many malformed PDFs instead of returning an error, e.g. on truncated files. Deferred
+// by every exported method, replaces the panic with an ExtractionError matching ErrCorruptPDF.
+func recoverCorruptPDF(err *error) {
+	if recovered := recover(); recovered != nil {
+		*err = &ExtractionError{
+			Binary: PURE_PDF_READER_VERSION,
+			Kind:   ErrCorruptPDF,
+			Err:    fmt.Errorf("%v", recovered),
+		}
+	}
+}
+
+func NewPurePDFReader() Reader {
+	return &PurePDFReader{}
+}
+
+func (p *PurePDFReader) Read(ctx context.Context, r io.Reader) (_ []byte, err error) {
+	defer recoverCorruptPDF(&err)
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := pdf.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+
+	pages := make([]string, 0, reader.NumPage())
+
+	for i := 1; i <= reader.NumPage(); i++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		text, err := p.readPage(reader.Page(i))
+		if err != nil {
+			return nil, err
+		}
+
+		pages = append(pages, text)
+	}
+
+	// Terminate every page with a form feed, just like pdftotext does
+	var text strings.Builder
+
+	for _, page := range pages {
+		text.WriteString(page)
+		text.WriteString("\f")
+	}
+
+	return []byte(text.String()), nil
+}
+
+func (p *PurePDFReader) readPage(page pdf.Page) (string, error) {
+	if page.V.IsNull() {
+		return "", nil
+	}
+
+	rows, err := page.GetTextByRow()
+	if err != nil {
+		return "", err
+	}
+
+	var text strings.Builder
+
+	for _, row := range rows {
+		var line strings.Builder
+
+		for _, word := range row.Content {
+			// Separate text blocks on the same row unless they already contain the whitespace
+			if line.Len() > 0 && !endsWithSpace(line.String()) && !startsWithSpace(word.S) {
+				line.WriteString(" ")
+			}
+
+			line.WriteString(word.S)
+		}
+
+		text.WriteString(strings.TrimRightFunc(line.String(), unicode.IsSpace))
+		text.WriteString("\n")
+	}
+
+	return normalizeCharacters(text.String()), nil
+}
+
+func (p *PurePDFReader) Metadata(ctx context.Context, r io.Reader) (_ *Metadata, err error) {
+	defer recoverCorruptPDF(&err)
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := pdf.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+
+	trailer := reader.Trailer()
+	info := trailer.Key("Info")
+
+	return &Metadata{
+		Pages:      reader.NumPage(),
+		Title:      info.Key("Title").Text(),
+		Producer:   info.Key("Producer").Text(),
+		CreatedAt:  parsePDFDate(info.Key("CreationDate").Text()),
+		ModifiedAt: parsePDFDate(info.Key("ModDate").Text()),
+		Encrypted:  !trailer.Key("Encrypt").IsNull(),
+	}, nil
+}
+
+func (p *PurePDFReader) Version() string {
+	return PURE_PDF_READER_VERSION
+}
+
+func startsWithSpace(s string) bool {
+	r, _ := utf8.DecodeRuneInString(s)
+
+	return unicode.IsSpace(r)
+}
+
+func endsWithSpace(s string) bool {
+	r, _ := utf8.DecodeLastRuneInString(s)
+
+	return unicode.IsSpace(r)
+}
@@ -0,0 +1,112 @@
+package pdf
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func readFixture(t *testing.T, reader Reader, name string) []string {
+	t.Helper()
+
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("could not read fixture '%s': %s", name, err)
+	}
+
+	text, err := reader.Read(context.Background(), bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("could not extract text from fixture '%s': %s", name, err)
+	}
+
+	return strings.Split(strings.TrimSuffix(string(text), "\f"), "\f")
+}
+
+func Test_PurePDFReader(t *testing.T) {
+	t.Run("Extracts the text of every page", func(t *testing.T) {
+		pages := readFixture(t, NewPurePDFReader(), "judgement.pdf")
+
+		assert.Len(t, pages, 2, "Should return the text of both pages")
+		assert.Contains(t, pages[0], "BUNDESGERICHTSHOF", "Should contain the court")
+		assert.Contains(t, pages[0], "I ZR 126/18", "Should contain the Aktenzeichen")
+	})
+
+	t.Run("Keeps German characters", func(t *testing.T) {
+		pages := readFixture(t, NewPurePDFReader(), "judgement.pdf")
+
+		assert.Contains(t, pages[0], "Verkündet am: 12. März 2020", "Should contain umlauts")
+		assert.Contains(t, pages[1], "Maßnahme", "Should contain the sharp s")
+		assert.Contains(t, pages[1], "§ 139 PatG", "Should contain the section sign")
+	})
+
+	t.Run("Replaces ligatures", func(t *testing.T) {
+		pages := readFixture(t, NewPurePDFReader(), "judgement.pdf")
+
+		assert.Contains(t, pages[1], "finanziell", "Should replace the fi ligature")
+		assert.Contains(t, pages[1], "verpflichtet", "Should replace the fl ligature")
+	})
+
+	t.Run("Returns an error for invalid PDFs", func(t *testing.T) {
+		_, err := NewPurePDFReader().Read(context.Background(), strings.NewReader("no pdf"))
+
+		assert.Error(t, err, "Should return an error")
+	})
+
+	t.Run("Returns an error for truncated PDFs", func(t *testing.T) {
+		data, err := os.ReadFile(filepath.Join("testdata", "judgement.pdf"))
+		if err != nil {
+			t.Fatalf("could not read fixture: %s", err)
+		}
+
+		// Keeps the trailer, so the pdf library panics when it follows the cross-reference table into the cut body
+		xref := bytes.LastIndex(data, []byte("\nxref")) + 1
+		truncated := append(append([]byte{}, data[:xref/2]...), data[xref:]...)
+
+		_, err = NewPurePDFReader().Read(context.Background(), bytes.NewReader(truncated))
+		assert.ErrorIs(t, err, ErrCorruptPDF, "Should report the text as corrupt")
+
+		_, err = NewPurePDFReader().Metadata(context.Background(), bytes.NewReader(truncated))
+		assert.ErrorIs(t, err, ErrCorruptPDF, "Should report the metadata as corrupt")
+	})
+}
+
+func Test_PurePDFReaderComparedToPopper(t *testing.T) {
+	if _, err := exec.LookPath("pdftotext"); err != nil {
+		t.Skip("pdftotext not found in PATH")
+	}
+
+	fixtures, err := filepath.Glob(filepath.Join("testdata", "*.pdf"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, fixture := range fixtures {
+		name := filepath.Base(fixture)
+
+		t.Run("Extracts the same words as pdftotext from "+name, func(t *testing.T) {
+			popper, err := NewPopperPDFReader(NewCommandRunner(CommandOptions{}))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			expected := readFixture(t, popper, name)
+			actual := readFixture(t, NewPurePDFReader(), name)
+
+			assert.Len(t, actual, len(expected), "Should return the same number of pages")
+
+			for i := range expected {
+				if i >= len(actual) {
+					break
+				}
+
+				assert.Equal(t, strings.Fields(normalizeCharacters(expected[i])), strings.Fields(actual[i]), "Should extract the same words on page %d", i+1)
+			}
+		})
+	}
+}
@@ -1,7 +1,18 @@
 package pdf
 
-import "context"
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+var ErrPageCountMismatch = errors.New("extracted page count does not match pdf")
 
 type Reader interface {
-	Read(ctx context.Context, path string) ([]byte, error)
+	// Returns the text of the PDF read from r, pages are separated by form feeds
+	Read(ctx context.Context, r io.Reader) ([]byte, error)
+	// Returns the document metadata of the PDF read from r, the file size is left empty
+	Metadata(ctx context.Context, r io.Reader) (*Metadata, error)
+	// Identifies the extractor and its version, e.g. "pdftotext 22.02.0"
+	Version() string
 }
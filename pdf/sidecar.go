@@ -0,0 +1,62 @@
+package pdf
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// Version of the sidecar format, increased on incompatible changes
+const SIDECAR_VERSION = 2
+
+type SidecarPage struct {
+	Page int    `json:"page"`
+	Text string `json:"text"`
+	// Either EXTRACTION_METHOD_TEXT or EXTRACTION_METHOD_OCR
+	Method string `json:"method"`
+}
+
+// Stores the extracted text of a PDF next to it, so the text can be re-chunked or re-embedded
+// without extracting it from the PDF again
+type Sidecar struct {
+	Version     int           `json:"version"`
+	FilePath    string        `json:"filePath"`
+	SourceURL   string        `json:"sourceUrl"`
+	ContentHash string        `json:"contentHash"`
+	Size        int           `json:"size"`
+	Extractor   string        `json:"extractor"`
+	OCR         string        `json:"ocr,omitempty"`
+	ExtractedAt time.Time     `json:"extractedAt"`
+	Metadata    *Metadata     `json:"metadata,omitempty"`
+	Pages       []SidecarPage `json:"pages"`
+}
+
+// Returns the path of the sidecar for the PDF at the given path
+func SidecarPath(path string) string {
+	return strings.TrimSuffix(path, ".pdf") + ".json"
+}
+
+func (s *Sidecar) Marshal() ([]byte, error) {
+	return json.MarshalIndent(s, "", "  ")
+}
+
+func UnmarshalSidecar(data []byte) (*Sidecar, error) {
+	var sidecar Sidecar
+
+	if err := json.Unmarshal(data, &sidecar); err != nil {
+		return nil, err
+	}
+
+	return &sidecar, nil
+}
+
+// Returns the text of all pages separated by form feeds, just like pdftotext does
+func (s *Sidecar) Text() string {
+	texts := make([]string, len(s.Pages))
+
+	for i, page := range s.Pages {
+		texts[i] = page.Text
+	}
+
+	return strings.Join(texts, "\f")
+}
@@ -0,0 +1,36 @@
+package pdf
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Sidecar(t *testing.T) {
+	t.Run("Creates the sidecar path next to the PDF", func(t *testing.T) {
+		assert.Equal(t, "judgements/bgh/2021/117424_3571_2950.json", SidecarPath("judgements/bgh/2021/117424_3571_2950.pdf"))
+	})
+
+	t.Run("Keeps the text when marshalling and unmarshalling", func(t *testing.T) {
+		sidecar := &Sidecar{
+			Version:     SIDECAR_VERSION,
+			FilePath:    "judgements/bgh/2021/117424_3571_2950.pdf",
+			ContentHash: "abc",
+			Extractor:   "pdftotext 22.02.0",
+			ExtractedAt: time.Date(2024, 8, 16, 0, 0, 0, 0, time.UTC),
+			Pages: []SidecarPage{
+				{Page: 1, Text: "BUNDESGERICHTSHOF", Method: EXTRACTION_METHOD_TEXT},
+				{Page: 2, Text: "Tatbestand", Method: EXTRACTION_METHOD_OCR},
+			},
+		}
+
+		data, err := sidecar.Marshal()
+		assert.NoError(t, err, "Should not return an error")
+
+		actual, err := UnmarshalSidecar(data)
+		assert.NoError(t, err, "Should not return an error")
+		assert.Equal(t, sidecar, actual, "Should return the same sidecar")
+		assert.Equal(t, "BUNDESGERICHTSHOF\fTatbestand", actual.Text(), "Should join the pages with form feeds")
+	})
+}
@@ -1,16 +1,20 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"errors"
-	"os"
+	"fmt"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/JuliusMoehring/court-judgment-finder-crawler/bgh"
+	"github.com/JuliusMoehring/court-judgment-finder-crawler/chunking"
 	"github.com/JuliusMoehring/court-judgment-finder-crawler/download"
 	"github.com/JuliusMoehring/court-judgment-finder-crawler/embedder"
 	filestorage "github.com/JuliusMoehring/court-judgment-finder-crawler/file-storage"
+	"github.com/JuliusMoehring/court-judgment-finder-crawler/judgement"
 	"github.com/JuliusMoehring/court-judgment-finder-crawler/logger"
 	"github.com/JuliusMoehring/court-judgment-finder-crawler/pdf"
 	vectorstore "github.com/JuliusMoehring/court-judgment-finder-crawler/vector-store"
@@ -21,18 +25,31 @@ type Processor struct {
 	downloader  download.Downloader
 	fileStorage filestorage.FileStorage
 	pdfReader   pdf.Reader
+	ocrReader   pdf.OCRReader
+	chunker     chunking.Chunker
 	embedder    embedder.Embedder
 	vectorStore vectorstore.VectorStore
+
+	// Bounds extracting the text of a document, zero for no bound
+	documentTimeout time.Duration
+
+	// Set once the embedding budget is exhausted, no further links are processed afterwards
+	budgetExceeded atomic.Bool
 }
 
-func NewProcessor(logger logger.Logger, downloader download.Downloader, fileStorage filestorage.FileStorage, pdfReader pdf.Reader, embedder embedder.Embedder, vectorStore vectorstore.VectorStore) *Processor {
+// The ocr reader is optional, without it pages without text are skipped. The document timeout bounds the
+// extraction of a whole document, the runner of the readers bounds every single binary.
+func NewProcessor(logger logger.Logger, downloader download.Downloader, fileStorage filestorage.FileStorage, pdfReader pdf.Reader, ocrReader pdf.OCRReader, chunker chunking.Chunker, embedder embedder.Embedder, vectorStore vectorstore.VectorStore, documentTimeout time.Duration) *Processor {
 	return &Processor{
-		logger:      logger,
-		downloader:  downloader,
-		fileStorage: fileStorage,
-		pdfReader:   pdfReader,
-		embedder:    embedder,
-		vectorStore: vectorStore,
+		logger:          logger,
+		downloader:      downloader,
+		fileStorage:     fileStorage,
+		pdfReader:       pdfReader,
+		ocrReader:       ocrReader,
+		chunker:         chunker,
+		embedder:        embedder,
+		vectorStore:     vectorStore,
+		documentTimeout: documentTimeout,
 	}
 }
 
@@ -60,39 +77,185 @@ func (p *Processor) shouldProcessLink(ctx context.Context, path string) (bool, e
 }
 
 func (p *Processor) pdfToText(ctx context.Context, data []byte) (string, error) {
-	if err := os.MkdirAll("temp", os.ModePerm); err != nil {
-		p.logger.Errorf("processor", "failed creating temp directory: %s", err)
+	text, err := p.pdfReader.Read(ctx, bytes.NewReader(data))
+	if err != nil {
+		p.logger.Errorf("processor", "failed reading pdf: %s", err)
 		return "", err
 	}
 
-	file, err := os.CreateTemp("temp", "temp-*.pdf")
-	if err != nil {
-		p.logger.Errorf("processor", "failed creating temp file: %s", err)
-		return "", err
+	return string(text), nil
+}
+
+func (p *Processor) newSidecar(link string, path string, contentHash string, data []byte, text string) *pdf.Sidecar {
+	sidecar := &pdf.Sidecar{
+		Version:     pdf.SIDECAR_VERSION,
+		FilePath:    path,
+		SourceURL:   link,
+		ContentHash: contentHash,
+		Size:        len(data),
+		Extractor:   p.pdfReader.Version(),
+		ExtractedAt: time.Now().UTC(),
+	}
+
+	// pdftotext terminates every page with a form feed
+	for i, page := range strings.Split(strings.TrimSuffix(text, "\f"), "\f") {
+		sidecar.Pages = append(sidecar.Pages, pdf.SidecarPage{
+			Page:   i + 1,
+			Text:   page,
+			Method: pdf.EXTRACTION_METHOD_TEXT,
+		})
 	}
 
-	defer func() {
-		if err := file.Close(); err != nil {
-			p.logger.Errorf("processor", "failed closing temp file: %s", err)
+	return sidecar
+}
+
+// Reads pages with no or implausibly little text with the ocr reader
+func (p *Processor) applyOCR(ctx context.Context, sidecar *pdf.Sidecar, data []byte) {
+	if p.ocrReader == nil {
+		return
+	}
+
+	for i, page := range sidecar.Pages {
+		if !pdf.NeedsOCR(page.Text) {
+			continue
 		}
 
-		if err := os.Remove(file.Name()); err != nil {
-			p.logger.Errorf("processor", "failed removing temp file: %s", err)
+		start := time.Now()
+		p.logger.Debugf("processor", "reading page %d of document %s with ocr", page.Page, sidecar.FilePath)
+
+		text, err := p.ocrReader.ReadPage(ctx, data, page.Page)
+		if err != nil {
+			p.logger.Warnf("processor", "failed reading page %d of document %s with ocr: %s", page.Page, sidecar.FilePath, err)
+			continue
 		}
-	}()
 
-	if _, err = file.Write(data); err != nil {
-		p.logger.Errorf("processor", "failed writing data to temp file: %s", err)
-		return "", err
+		p.logger.Debugf("processor", "read page %d of document %s with ocr, took: %s", page.Page, sidecar.FilePath, time.Since(start))
+
+		if len(strings.TrimSpace(string(text))) <= len(strings.TrimSpace(page.Text)) {
+			continue
+		}
+
+		sidecar.Pages[i].Text = string(text)
+		sidecar.Pages[i].Method = pdf.EXTRACTION_METHOD_OCR
+		sidecar.OCR = p.ocrReader.Version()
+	}
+}
+
+// Returns the stored sidecar of the document if it was extracted from the same PDF by the same extractor, nil otherwise
+func (p *Processor) loadSidecar(ctx context.Context, path string, contentHash string) (*pdf.Sidecar, error) {
+	data, err := p.fileStorage.Read(ctx, pdf.SidecarPath(path))
+	if errors.Is(err, filestorage.ErrFileNotFound) {
+		return nil, nil
 	}
 
-	bytes, err := p.pdfReader.Read(ctx, file.Name())
 	if err != nil {
-		p.logger.Errorf("processor", "failed reading temp file: %s", err)
-		return "", err
+		p.logger.Errorf("processor", "failed reading sidecar: %s", err)
+		return nil, err
 	}
 
-	return string(bytes), nil
+	sidecar, err := pdf.UnmarshalSidecar(data)
+	if err != nil {
+		p.logger.Warnf("processor", "ignoring invalid sidecar of document '%s': %s", path, err)
+		return nil, nil
+	}
+
+	if sidecar.Version != pdf.SIDECAR_VERSION || sidecar.ContentHash != contentHash || sidecar.Extractor != p.pdfReader.Version() {
+		p.logger.Debugf("processor", "ignoring outdated sidecar of document '%s'", path)
+		return nil, nil
+	}
+
+	if p.ocrReader != nil && sidecar.OCR != p.ocrReader.Version() && needsOCR(sidecar) {
+		p.logger.Debugf("processor", "ignoring sidecar of document '%s' read with a different ocr reader", path)
+		return nil, nil
+	}
+
+	p.logger.Debugf("processor", "using stored sidecar of document '%s'", path)
+
+	return sidecar, nil
+}
+
+// Returns whether pages of the sidecar were or would be read with ocr
+func needsOCR(sidecar *pdf.Sidecar) bool {
+	for _, page := range sidecar.Pages {
+		if page.Method == pdf.EXTRACTION_METHOD_OCR || pdf.NeedsOCR(page.Text) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p *Processor) saveSidecar(ctx context.Context, sidecar *pdf.Sidecar) error {
+	data, err := sidecar.Marshal()
+	if err != nil {
+		p.logger.Errorf("processor", "failed marshalling sidecar: %s", err)
+		return err
+	}
+
+	if err := p.fileStorage.Save(ctx, data, pdf.SidecarPath(sidecar.FilePath)); err != nil {
+		p.logger.Errorf("processor", "failed saving sidecar to file storage: %s", err)
+		return err
+	}
+
+	return nil
+}
+
+// Extracts the metadata from the header of the judgement and reports differences to the court and year the
+// crawler found the judgement under
+func (p *Processor) judgementMetadata(path string, sections []judgement.Section) *vectorstore.JudgementMetadata {
+	metadata := judgement.ExtractMetadata(sections)
+
+	var mismatches []string
+
+	if pathMetadata, err := bgh.MetadataFromPath(path); err == nil {
+		mismatches = metadata.Mismatches(pathMetadata.Court, pathMetadata.Year)
+	}
+
+	for _, mismatch := range mismatches {
+		p.logger.Warnf("processor", "metadata mismatch in document %s: %s", path, mismatch)
+	}
+
+	if metadata.FileNumber == "" {
+		p.logger.Warnf("processor", "no Aktenzeichen found in document %s", path)
+	}
+
+	return &vectorstore.JudgementMetadata{
+		FileNumber:      metadata.FileNumber,
+		Date:            metadata.Date,
+		DecisionType:    metadata.DecisionType,
+		Court:           metadata.Court,
+		Senate:          metadata.Senate,
+		ECLI:            metadata.ECLI,
+		Nachschlagewerk: metadata.Nachschlagewerk,
+		BGHZ:            metadata.BGHZ,
+		BGHR:            metadata.BGHR,
+		Norms:           metadata.Norms,
+		Mismatches:      mismatches,
+	}
+}
+
+// Extracts the citations of other decisions, the judgement citing itself in the Leitsatz is ignored
+func (p *Processor) citations(path string, pages []pdf.NormalizedPage, metadata *vectorstore.JudgementMetadata) []vectorstore.CreateDocumentParamsCitation {
+	var citations []vectorstore.CreateDocumentParamsCitation
+
+	for _, citation := range judgement.ExtractCitations(pages) {
+		if citation.Court == metadata.Court && citation.FileNumber == metadata.FileNumber {
+			continue
+		}
+
+		citations = append(citations, vectorstore.CreateDocumentParamsCitation{
+			Court:        citation.Court,
+			DecisionType: citation.DecisionType,
+			Date:         citation.Date,
+			FileNumber:   citation.FileNumber,
+			Page:         citation.Page,
+			Text:         citation.Text,
+		})
+	}
+
+	p.logger.Debugf("processor", "found %d citations in document %s", len(citations), path)
+
+	return citations
 }
 
 func (p *Processor) processLink(ctx context.Context, link string) error {
@@ -132,52 +295,261 @@ func (p *Processor) processLink(ctx context.Context, link string) error {
 
 	p.logger.Debugf("processor", "saved document to file storage: %s, took: %s", link, time.Since(start))
 
-	start = time.Now()
-	p.logger.Debugf("processor", "converting pdf to text: %s", link)
+	return p.ingest(ctx, link, path, data)
+}
+
+// Returns whether a document was skipped because the embedding budget is exhausted
+func (p *Processor) BudgetExceeded() bool {
+	return p.budgetExceeded.Load()
+}
 
-	text, err := p.pdfToText(ctx, data)
+// Extracts the text of a stored PDF, embeds it and creates the document in the vector store.
+// The link is only used for the sidecar and may be empty if the source of the PDF is unknown.
+func (p *Processor) ingest(ctx context.Context, link string, path string, data []byte) error {
+	params, err := p.prepare(ctx, link, path, data, true)
 	if err != nil {
-		p.logger.Errorf("processor", "failed converting pdf to text: %s", err)
 		return err
 	}
 
-	p.logger.Debugf("processor", "converted pdf to text: %s, took: %s", link, time.Since(start))
+	texts := make([]string, len(params.Chunks))
 
-	pages := strings.Split(text, "\f")
+	for i, chunk := range params.Chunks {
+		texts[i] = chunk.Text
+	}
+
+	start := time.Now()
+	p.logger.Debugf("processor", "creating embeddings for %d chunks of document %s", len(texts), path)
+
+	embeddings, err := p.embedder.EmbedBatch(ctx, texts)
+	// The document is processed again by the next run
+	if errors.Is(err, embedder.ErrBudgetExceeded) {
+		p.logger.Warnf("processor", "skipping document %s: %s", path, err)
+
+		if p.budgetExceeded.CompareAndSwap(false, true) {
+			p.logger.Warnf("processor", "embedding budget exhausted, the remaining links are left for the next run")
+		}
+
+		return nil
+	}
+
+	if err != nil {
+		p.logger.Errorf("processor", "failed to create embeddings for document %s: %s", path, err)
+		return err
+	}
+
+	p.logger.Debugf("processor", "created embeddings for %d chunks of document %s, took: %s", len(texts), path, time.Since(start))
+
+	for i := range params.Chunks {
+		params.Chunks[i].Embedding = embeddings[i]
+	}
+
+	p.logger.Debugf("processor", "creating document in vector store: %s", path)
+
+	return p.vectorStore.CreateDocument(ctx, *params)
+}
+
+// Extracts the text of the PDF and returns the document without embeddings. New sidecars are only saved if
+// saveSidecar is set.
+func (p *Processor) prepare(ctx context.Context, link string, path string, data []byte, saveSidecar bool) (*vectorstore.CreateDocumentParams, error) {
+	contentHash := filestorage.ContentHash(data)
+
+	sidecar, err := p.loadSidecar(ctx, path, contentHash)
+	if err != nil {
+		return nil, err
+	}
+
+	// A malformed PDF must not keep a worker busy with one binary after another
+	extractCtx := ctx
+
+	if p.documentTimeout > 0 {
+		var cancel context.CancelFunc
+		extractCtx, cancel = context.WithTimeout(ctx, p.documentTimeout)
+		defer cancel()
+	}
+
+	if sidecar == nil {
+		start := time.Now()
+		p.logger.Debugf("processor", "converting pdf to text: %s", path)
+
+		text, err := p.pdfToText(extractCtx, data)
+		if err != nil {
+			p.logger.Errorf("processor", "failed converting pdf to text: %s", err)
+			return nil, err
+		}
+
+		p.logger.Debugf("processor", "converted pdf to text: %s, took: %s", path, time.Since(start))
+
+		sidecar = p.newSidecar(link, path, contentHash, data, text)
+
+		p.applyOCR(extractCtx, sidecar, data)
+	}
+
+	if sidecar.Metadata == nil {
+		metadata, err := p.pdfReader.Metadata(extractCtx, bytes.NewReader(data))
+		if err != nil {
+			p.logger.Errorf("processor", "failed reading pdf metadata: %s", err)
+			return nil, err
+		}
+
+		// Pages whose ocr ran out of time are missing, the sidecar must not be saved without them
+		if errors.Is(extractCtx.Err(), context.DeadlineExceeded) {
+			p.logger.Errorf("processor", "extracting document %s took longer than %s", path, p.documentTimeout)
+			return nil, fmt.Errorf("extracting document %s took longer than %s: %w", path, p.documentTimeout, pdf.ErrTimeout)
+		}
+
+		metadata.FileSize = len(data)
+		sidecar.Metadata = metadata
+
+		if saveSidecar {
+			if err := p.saveSidecar(ctx, sidecar); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := sidecar.Metadata.CheckPageCount(len(sidecar.Pages)); err != nil {
+		p.logger.Errorf("processor", "failed sanity check of document %s: %s", path, err)
+		return nil, err
+	}
+
+	texts := make([]string, len(sidecar.Pages))
+
+	for i, page := range sidecar.Pages {
+		texts[i] = page.Text
+	}
+
+	pages := pdf.Normalize(texts)
+	sections := judgement.Parse(pages)
+
+	p.logger.Debugf("processor", "found %d sections in document %s", len(sections), path)
+
+	judgementMetadata := p.judgementMetadata(path, sections)
+	citations := p.citations(path, pages, judgementMetadata)
+
+	var statuteReferences []vectorstore.CreateDocumentParamsStatuteReference
+
+	for _, reference := range judgement.ExtractStatuteReferences(pages) {
+		statuteReferences = append(statuteReferences, vectorstore.CreateDocumentParamsStatuteReference{
+			Page:      reference.Page,
+			Law:       reference.Law,
+			Kind:      reference.Kind,
+			Section:   reference.Section,
+			Paragraph: reference.Paragraph,
+			Sentence:  reference.Sentence,
+			Number:    reference.Number,
+			Reference: reference.String(),
+		})
+	}
+
+	p.logger.Debugf("processor", "found %d statute references in document %s", len(statuteReferences), path)
 
 	var judgementPages []vectorstore.CreateDocumentParamsPage
 
 	for i, page := range pages {
-		if len(page) == 0 {
+		if len(page.Text) == 0 {
 			continue
 		}
 
-		p.logger.Debugf("processor", "creating embeddings for page %d/%d of link %s", i+1, len(pages), link)
-
-		embedding, err := p.embedder.Embed(ctx, page)
-		if err != nil {
-			p.logger.Errorf("processor", "failed to create embddings for page %d/%d of link %s: %s", i+1, len(pages), link, err)
-			return err
+		method := sidecar.Pages[i].Method
+		if method == "" {
+			method = pdf.EXTRACTION_METHOD_TEXT
 		}
 
-		p.logger.Debugf("processor", "created embeddings for page %d/%d of link %s", i+1, len(pages), link)
+		sectionTypes := []string{}
+
+		for _, sectionType := range judgement.PageSectionTypes(sections, page.Page) {
+			sectionTypes = append(sectionTypes, string(sectionType))
+		}
 
 		judgementPages = append(judgementPages, vectorstore.CreateDocumentParamsPage{
-			Text:      page,
-			Embedding: embedding,
+			Page:             page.Page,
+			Text:             page.Text,
+			ExtractionMethod: method,
+			SectionTypes:     sectionTypes,
 		})
 	}
 
-	p.logger.Debugf("processor", "creating document in vector store: %s", link)
+	chunks := p.chunker.Chunk(pages)
+	judgementChunks := make([]vectorstore.CreateDocumentParamsChunk, len(chunks))
+
+	p.logger.Debugf("processor", "split document %s into %d chunks with strategy %s", path, len(chunks), p.chunker.Strategy())
+
+	for i, chunk := range chunks {
+		judgementChunks[i] = vectorstore.CreateDocumentParamsChunk{
+			Text:              chunk.Text,
+			StartOffset:       chunk.Start,
+			EndOffset:         chunk.End,
+			StartPage:         chunk.StartPage,
+			EndPage:           chunk.EndPage,
+			StartMarginNumber: chunk.StartMarginNumber,
+			EndMarginNumber:   chunk.EndMarginNumber,
+			SectionTypes:      chunkSectionTypes(sections, chunk),
+		}
+	}
+
+	judgementSections := make([]vectorstore.CreateDocumentParamsSection, len(sections))
+
+	for i, section := range sections {
+		judgementSections[i] = vectorstore.CreateDocumentParamsSection{
+			Type:              string(section.Type),
+			Text:              section.Text,
+			StartPage:         section.StartPage,
+			EndPage:           section.EndPage,
+			StartMarginNumber: section.StartMarginNumber,
+			EndMarginNumber:   section.EndMarginNumber,
+		}
+	}
+
+	return &vectorstore.CreateDocumentParams{
+		FilePath:    path,
+		ContentHash: contentHash,
+		Judgement:   judgementMetadata,
+		Metadata: &vectorstore.DocumentMetadata{
+			PageCount:  sidecar.Metadata.Pages,
+			Title:      sidecar.Metadata.Title,
+			Producer:   sidecar.Metadata.Producer,
+			CreatedAt:  sidecar.Metadata.CreatedAt,
+			ModifiedAt: sidecar.Metadata.ModifiedAt,
+			Encrypted:  sidecar.Metadata.Encrypted,
+			FileSize:   sidecar.Metadata.FileSize,
+		},
+		Pages:               judgementPages,
+		ChunkingStrategy:    p.chunker.Strategy(),
+		Chunks:              judgementChunks,
+		EmbeddingModel:      p.embedder.Model(),
+		EmbeddingDimensions: p.embedder.Dimensions(),
+		Sections:            judgementSections,
+		Citations:           citations,
+		StatuteReferences:   statuteReferences,
+	}, nil
+}
+
+// Returns the types of the judgement sections on the pages of the chunk
+func chunkSectionTypes(sections []judgement.Section, chunk chunking.Chunk) []string {
+	sectionTypes := []string{}
+	seen := map[judgement.SectionType]bool{}
 
-	return p.vectorStore.CreateDocument(ctx, vectorstore.CreateDocumentParams{
-		FilePath: path,
-		Pages:    judgementPages,
-	})
+	for page := chunk.StartPage; page <= chunk.EndPage; page++ {
+		for _, sectionType := range judgement.PageSectionTypes(sections, page) {
+			if seen[sectionType] {
+				continue
+			}
+
+			seen[sectionType] = true
+			sectionTypes = append(sectionTypes, string(sectionType))
+		}
+	}
+
+	return sectionTypes
 }
 
 func (p *Processor) Process(ctx context.Context, downloadLinks <-chan string, errors chan<- error) {
 	for link := range downloadLinks {
+		// Downloading and extracting further documents is pointless if they cannot be embedded
+		if p.BudgetExceeded() {
+			return
+		}
+
 		p.logger.Debugf("processor", "processing link: '%s'", link)
 
 		if err := p.processLink(ctx, link); err != nil {
@@ -0,0 +1,298 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/JuliusMoehring/court-judgment-finder-crawler/chunking"
+	"github.com/JuliusMoehring/court-judgment-finder-crawler/embedder"
+	"github.com/JuliusMoehring/court-judgment-finder-crawler/logger"
+	"github.com/JuliusMoehring/court-judgment-finder-crawler/pdf"
+	fakes "github.com/JuliusMoehring/court-judgment-finder-crawler/testing"
+	vectorstore "github.com/JuliusMoehring/court-judgment-finder-crawler/vector-store"
+)
+
+const (
+	testLink = "https://juris.bundesgerichtshof.de/cgi-bin/rechtsprechung/document.py?Gericht=bgh&Art=en&Datum=2021&Seite=98&nr=117424&anz=3571&pos=2950&Blank=1.pdf"
+	testPath = "judgements/bgh/2021/117424_3571_2950.pdf"
+)
+
+var testPDF = fakes.FakePDF(
+	"BUNDESGERICHTSHOF\n\nURTEIL\n\n1\n\nDie Klägerin vertreibt Kaffee.\n\n2\n\nDie Beklagte",
+	"",
+	"bewirbt Tee.\n\n3\n\nDie Revision hat Erfolg. Das Urteil wird aufgehoben.\n",
+)
+
+type testProcessor struct {
+	*Processor
+
+	downloader  *fakes.FakeDownloader
+	fileStorage *fakes.FakeFileStorage
+	pdfReader   *fakes.FakePDFReader
+	embedder    *fakes.FakeEmbedder
+	vectorStore *fakes.FakeVectorStore
+}
+
+func newTestProcessor(ocrReader pdf.OCRReader) *testProcessor {
+	p := &testProcessor{
+		downloader:  fakes.NewFakeDownloader(map[string][]byte{testLink: testPDF}),
+		fileStorage: fakes.NewFakeFileStorage(nil),
+		pdfReader:   &fakes.FakePDFReader{},
+		embedder:    fakes.NewFakeEmbedder(8),
+		vectorStore: fakes.NewFakeVectorStore(fakes.FAKE_EMBEDDING_MODEL, 8),
+	}
+
+	chunker := chunking.NewMarginNumberChunker(embedder.EstimateTokens, DEFAULT_CHUNK_SIZE)
+
+	p.Processor = NewProcessor(logger.NewStdOutLogger(), p.downloader, p.fileStorage, p.pdfReader, ocrReader, chunker, p.embedder, p.vectorStore, DEFAULT_DOCUMENT_TIMEOUT)
+
+	return p
+}
+
+// Processes the links with a single worker and returns the reported errors
+func (p *testProcessor) process(links ...string) []error {
+	downloadLinks := make(chan string, len(links))
+	errs := make(chan error, len(links))
+
+	for _, link := range links {
+		downloadLinks <- link
+	}
+
+	close(downloadLinks)
+
+	p.Process(context.Background(), downloadLinks, errs)
+
+	close(errs)
+
+	var reported []error
+
+	for err := range errs {
+		reported = append(reported, err)
+	}
+
+	return reported
+}
+
+// Reads pages with ocr until the context is done
+type blockingOCRReader struct{}
+
+func (r blockingOCRReader) ReadPage(ctx context.Context, data []byte, page int) ([]byte, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func (r blockingOCRReader) Version() string {
+	return "blocking-ocr-reader 1.0"
+}
+
+func Test_Processor(t *testing.T) {
+	t.Run("Stores, chunks and embeds new documents", func(t *testing.T) {
+		p := newTestProcessor(nil)
+
+		assert.Empty(t, p.process(testLink), "Should not report errors")
+
+		data, err := p.fileStorage.Read(context.Background(), testPath)
+		assert.NoError(t, err, "Should save the PDF")
+		assert.Equal(t, testPDF, data, "Should save the downloaded data")
+
+		exists, _ := p.fileStorage.Exists(context.Background(), pdf.SidecarPath(testPath))
+		assert.True(t, exists, "Should save the sidecar")
+
+		document, ok := p.vectorStore.Document(testPath)
+		assert.True(t, ok, "Should create the document")
+		assert.Equal(t, chunking.STRATEGY_MARGIN_NUMBERS, document.ChunkingStrategy, "Should store the chunking strategy")
+		assert.Equal(t, fakes.FAKE_EMBEDDING_MODEL, document.EmbeddingModel, "Should store the embedding model")
+		assert.Equal(t, 3, document.Metadata.PageCount, "Should store the metadata")
+		assert.NotEmpty(t, document.Chunks, "Should create chunks")
+
+		for _, chunk := range document.Chunks {
+			assert.Equal(t, fakes.FakeEmbedding(chunk.Text, 8), chunk.Embedding, "Should store the embedding of the chunk")
+		}
+
+		assert.Len(t, p.embedder.Texts(), len(document.Chunks), "Should embed every chunk once")
+	})
+
+	t.Run("Skips empty pages", func(t *testing.T) {
+		p := newTestProcessor(nil)
+
+		assert.Empty(t, p.process(testLink), "Should not report errors")
+
+		document, _ := p.vectorStore.Document(testPath)
+
+		var pages []int
+
+		for _, page := range document.Pages {
+			pages = append(pages, page.Page)
+		}
+
+		assert.Equal(t, []int{1, 3}, pages, "Should not store the empty page")
+	})
+
+	t.Run("Reads pages without text with ocr", func(t *testing.T) {
+		p := newTestProcessor(&fakes.FakeOCRReader{Pages: map[int]string{2: "Die Klägerin trinkt Kaffee und bezahlt ihn."}})
+
+		assert.Empty(t, p.process(testLink), "Should not report errors")
+
+		document, _ := p.vectorStore.Document(testPath)
+
+		assert.Len(t, document.Pages, 3, "Should store the page read with ocr")
+		assert.Equal(t, pdf.EXTRACTION_METHOD_OCR, document.Pages[1].ExtractionMethod, "Should store the extraction method")
+	})
+
+	t.Run("Skips documents that are stored and in the vector store", func(t *testing.T) {
+		p := newTestProcessor(nil)
+
+		assert.Empty(t, p.process(testLink), "Should not report errors")
+		assert.Empty(t, p.process(testLink), "Should not report errors")
+
+		assert.Len(t, p.downloader.Downloads(), 1, "Should download the document once")
+	})
+
+	t.Run("Processes stored documents missing from the vector store", func(t *testing.T) {
+		p := newTestProcessor(nil)
+
+		assert.Empty(t, p.process(testLink), "Should not report errors")
+		assert.NoError(t, p.vectorStore.DeleteDocument(context.Background(), testPath), "Should delete the document")
+		assert.Empty(t, p.process(testLink), "Should not report errors")
+
+		_, ok := p.vectorStore.Document(testPath)
+		assert.True(t, ok, "Should create the document again")
+	})
+
+	t.Run("Ignores links that are not BGH documents", func(t *testing.T) {
+		p := newTestProcessor(nil)
+
+		assert.Empty(t, p.process("https://juris.bundesgerichtshof.de/cgi-bin/rechtsprechung/list.py"), "Should not report errors")
+		assert.Empty(t, p.downloader.Downloads(), "Should not download anything")
+	})
+
+	t.Run("Reports failed downloads and continues", func(t *testing.T) {
+		p := newTestProcessor(nil)
+
+		otherLink := "https://juris.bundesgerichtshof.de/cgi-bin/rechtsprechung/document.py?Gericht=bgh&Art=en&Datum=2021&nr=1&anz=1&pos=0"
+
+		errs := p.process(otherLink, testLink)
+
+		assert.Len(t, errs, 1, "Should report the failed download")
+		assert.ErrorIs(t, errs[0], fakes.ErrNotFound, "Should report the error of the downloader")
+
+		_, ok := p.vectorStore.Document(testPath)
+		assert.True(t, ok, "Should process the other link")
+	})
+
+	t.Run("Reports errors of the file storage", func(t *testing.T) {
+		p := newTestProcessor(nil)
+		p.fileStorage.Err = errors.New("unavailable")
+
+		assert.Len(t, p.process(testLink), 1, "Should report the error")
+		assert.Empty(t, p.downloader.Downloads(), "Should not download the document")
+	})
+
+	t.Run("Reports errors of the vector store", func(t *testing.T) {
+		p := newTestProcessor(nil)
+		p.vectorStore.Err = errors.New("unavailable")
+
+		assert.Len(t, p.process(testLink), 1, "Should report the error")
+	})
+
+	t.Run("Reports errors of the pdf reader", func(t *testing.T) {
+		p := newTestProcessor(nil)
+		p.pdfReader.ReadErr = errors.New("damaged")
+
+		assert.Len(t, p.process(testLink), 1, "Should report the error")
+
+		_, ok := p.vectorStore.Document(testPath)
+		assert.False(t, ok, "Should not create the document")
+	})
+
+	t.Run("Reports documents taking longer than the document timeout", func(t *testing.T) {
+		p := newTestProcessor(blockingOCRReader{})
+		p.documentTimeout = 10 * time.Millisecond
+
+		errs := p.process(testLink)
+		assert.Len(t, errs, 1, "Should report the timeout")
+		assert.ErrorIs(t, errs[0], pdf.ErrTimeout, "Should report a timeout")
+
+		exists, _ := p.fileStorage.Exists(context.Background(), pdf.SidecarPath(testPath))
+		assert.False(t, exists, "Should not save the incomplete sidecar")
+	})
+
+	t.Run("Reports documents with a different page count", func(t *testing.T) {
+		p := newTestProcessor(nil)
+		p.pdfReader.ExtraPages = 1
+
+		errs := p.process(testLink)
+
+		assert.Len(t, errs, 1, "Should report the error")
+		assert.ErrorIs(t, errs[0], pdf.ErrPageCountMismatch, "Should fail the sanity check")
+	})
+
+	t.Run("Reports documents embedded with another model than the active one", func(t *testing.T) {
+		p := newTestProcessor(nil)
+		p.vectorStore = fakes.NewFakeVectorStore("text-embedding-3-small", 8)
+		p.Processor.vectorStore = p.vectorStore
+
+		errs := p.process(testLink)
+
+		assert.Len(t, errs, 1, "Should report the error")
+		assert.ErrorIs(t, errs[0], vectorstore.ErrEmbeddingModelMismatch, "Should reject the embeddings")
+	})
+
+	t.Run("Reports errors of the embedder", func(t *testing.T) {
+		p := newTestProcessor(nil)
+		p.embedder.Err = errors.New("unavailable")
+
+		assert.Len(t, p.process(testLink), 1, "Should report the error")
+
+		_, ok := p.vectorStore.Document(testPath)
+		assert.False(t, ok, "Should not create the document")
+	})
+
+	t.Run("Skips documents exceeding the embedding budget", func(t *testing.T) {
+		p := newTestProcessor(nil)
+		p.embedder.Err = embedder.ErrBudgetExceeded
+
+		assert.Empty(t, p.process(testLink), "Should not report an error")
+
+		_, ok := p.vectorStore.Document(testPath)
+		assert.False(t, ok, "Should leave the document for the next run")
+	})
+
+	t.Run("Stops processing links once the embedding budget is exhausted", func(t *testing.T) {
+		p := newTestProcessor(nil)
+		p.embedder.Err = embedder.ErrBudgetExceeded
+
+		otherLink := "https://juris.bundesgerichtshof.de/cgi-bin/rechtsprechung/document.py?Gericht=bgh&Art=en&Datum=2021&nr=1&anz=1&pos=0"
+
+		assert.Empty(t, p.process(testLink, otherLink), "Should not report an error")
+		assert.True(t, p.BudgetExceeded(), "Should report the exhausted budget")
+		assert.Equal(t, []string{testLink}, p.downloader.Downloads(), "Should not download further documents")
+	})
+
+	t.Run("Uses the stored sidecar instead of reading the PDF again", func(t *testing.T) {
+		p := newTestProcessor(nil)
+
+		assert.Empty(t, p.process(testLink), "Should not report errors")
+
+		p.pdfReader.ReadErr = errors.New("damaged")
+
+		assert.NoError(t, p.ingest(context.Background(), "", testPath, testPDF), "Should not read the PDF")
+	})
+
+	t.Run("Reads the PDF again when the sidecar was read without the ocr reader", func(t *testing.T) {
+		p := newTestProcessor(nil)
+
+		assert.Empty(t, p.process(testLink), "Should not report errors")
+
+		p.ocrReader = &fakes.FakeOCRReader{Pages: map[int]string{2: "Die Klägerin trinkt Kaffee und bezahlt ihn."}}
+
+		assert.NoError(t, p.ingest(context.Background(), "", testPath, testPDF), "Should not return an error")
+
+		document, _ := p.vectorStore.Document(testPath)
+		assert.Len(t, document.Pages, 3, "Should store the page read with ocr")
+	})
+}
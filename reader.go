@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"strconv"
+	"time"
+
+	"github.com/JuliusMoehring/court-judgment-finder-crawler/logger"
+	"github.com/JuliusMoehring/court-judgment-finder-crawler/pdf"
+)
+
+const (
+	DEFAULT_PDF_EXTRACTION_TIMEOUT    = 2 * time.Minute
+	DEFAULT_DOCUMENT_TIMEOUT          = 10 * time.Minute
+	DEFAULT_PDF_EXTRACTION_MAX_OUTPUT = 50 * 1024 * 1024
+)
+
+// Creates the runner shared by all extraction binaries, configured by PDF_EXTRACTION_TIMEOUT (duration like "90s"),
+// PDF_EXTRACTION_CONCURRENCY and PDF_EXTRACTION_MAX_OUTPUT (bytes)
+func newCommandRunner() (*pdf.CommandRunner, error) {
+	options := pdf.CommandOptions{
+		Timeout:        DEFAULT_PDF_EXTRACTION_TIMEOUT,
+		MaxConcurrency: runtime.NumCPU(),
+		MaxOutputSize:  DEFAULT_PDF_EXTRACTION_MAX_OUTPUT,
+	}
+
+	if value := os.Getenv("PDF_EXTRACTION_TIMEOUT"); value != "" {
+		timeout, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid PDF_EXTRACTION_TIMEOUT: %w", err)
+		}
+
+		options.Timeout = timeout
+	}
+
+	if value := os.Getenv("PDF_EXTRACTION_CONCURRENCY"); value != "" {
+		concurrency, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid PDF_EXTRACTION_CONCURRENCY: %w", err)
+		}
+
+		options.MaxConcurrency = concurrency
+	}
+
+	if value := os.Getenv("PDF_EXTRACTION_MAX_OUTPUT"); value != "" {
+		maxOutput, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid PDF_EXTRACTION_MAX_OUTPUT: %w", err)
+		}
+
+		options.MaxOutputSize = maxOutput
+	}
+
+	return pdf.NewCommandRunner(options), nil
+}
+
+// Returns the time the text of a whole document may take to extract, including ocr of all its pages, from
+// DOCUMENT_EXTRACTION_TIMEOUT (duration like "10m"). Every binary is additionally bound by PDF_EXTRACTION_TIMEOUT.
+func newDocumentTimeout() (time.Duration, error) {
+	value := os.Getenv("DOCUMENT_EXTRACTION_TIMEOUT")
+	if value == "" {
+		return DEFAULT_DOCUMENT_TIMEOUT, nil
+	}
+
+	timeout, err := time.ParseDuration(value)
+	if err != nil || timeout <= 0 {
+		return 0, fmt.Errorf("invalid DOCUMENT_EXTRACTION_TIMEOUT: '%s'", value)
+	}
+
+	return timeout, nil
+}
+
+// Creates the pdf reader configured by PDF_READER (popper or pure), defaults to popper
+func newPDFReader(runner *pdf.CommandRunner) (pdf.Reader, error) {
+	switch reader := os.Getenv("PDF_READER"); reader {
+	case "", "popper":
+		return pdf.NewPopperPDFReader(runner)
+	case "pure":
+		return pdf.NewPurePDFReader(), nil
+	default:
+		return nil, fmt.Errorf("unknown pdf reader: '%s'", reader)
+	}
+}
+
+// Creates the ocr reader unless OCR_ENABLED is "false", returns nil if the required binaries are missing
+func newOCRReader(logger logger.Logger, runner *pdf.CommandRunner) pdf.OCRReader {
+	if os.Getenv("OCR_ENABLED") == "false" {
+		return nil
+	}
+
+	ocrReader, err := pdf.NewTesseractOCRReader(runner)
+	if err != nil {
+		logger.Warnf("main", "ocr is disabled: %s", err)
+		return nil
+	}
+
+	return ocrReader
+}
@@ -0,0 +1,258 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+
+	filestorage "github.com/JuliusMoehring/court-judgment-finder-crawler/file-storage"
+	"github.com/JuliusMoehring/court-judgment-finder-crawler/logger"
+	"github.com/JuliusMoehring/court-judgment-finder-crawler/pdf"
+	vectorstore "github.com/JuliusMoehring/court-judgment-finder-crawler/vector-store"
+)
+
+const JUDGEMENTS_PREFIX = "judgements/"
+
+type reconcileReport struct {
+	// PDFs in the file storage without a document in the vector store
+	orphanedFiles []string
+	// Documents in the vector store without a PDF in the file storage
+	orphanedDocuments []string
+	// Documents whose content hash does not match the stored PDF
+	mismatches []string
+	// PDFs that could not be read to verify their content hash
+	unreadable map[string]error
+	// Contents of the content addressed layout that no path refers to anymore
+	unreferencedObjects []string
+}
+
+// Compares the PDFs in the file storage with the documents in the vector store
+func reconcile(ctx context.Context, logger logger.Logger, fileStorage filestorage.FileStorage, vectorStore vectorstore.VectorStore, verifyHashes bool) (*reconcileReport, error) {
+	paths, err := fileStorage.List(ctx, JUDGEMENTS_PREFIX)
+	if err != nil {
+		return nil, err
+	}
+
+	files := map[string]bool{}
+
+	for _, path := range paths {
+		// Sidecars and other files stored next to the PDFs are not documents
+		if strings.HasSuffix(path, ".pdf") {
+			files[path] = true
+		}
+	}
+
+	documents, err := vectorStore.ListDocuments(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Infof("reconcile", "found %d files and %d documents", len(files), len(documents))
+
+	report := &reconcileReport{unreadable: map[string]error{}}
+	documentPaths := map[string]bool{}
+
+	for _, document := range documents {
+		documentPaths[document.FilePath] = true
+
+		if !files[document.FilePath] {
+			report.orphanedDocuments = append(report.orphanedDocuments, document.FilePath)
+			continue
+		}
+
+		if !verifyHashes || document.ContentHash == "" {
+			continue
+		}
+
+		data, err := fileStorage.Read(ctx, document.FilePath)
+		if err != nil {
+			logger.Errorf("reconcile", "failed reading '%s': %s", document.FilePath, err)
+			report.unreadable[document.FilePath] = err
+			continue
+		}
+
+		if filestorage.ContentHash(data) != document.ContentHash {
+			report.mismatches = append(report.mismatches, document.FilePath)
+		}
+	}
+
+	for path := range files {
+		if !documentPaths[path] {
+			report.orphanedFiles = append(report.orphanedFiles, path)
+		}
+	}
+
+	sort.Strings(report.orphanedFiles)
+
+	// Deleting or replacing a file only removes its index entry, the content stays behind
+	if contentAddressed, ok := fileStorage.(*filestorage.ContentAddressedFileStorage); ok {
+		report.unreferencedObjects, err = contentAddressed.UnreferencedObjects(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return report, nil
+}
+
+// Reports and optionally repairs differences between the file storage and the vector store
+func runReconcile(ctx context.Context, logger logger.Logger, args []string) error {
+	flags := flag.NewFlagSet("reconcile", flag.ExitOnError)
+
+	storage := flags.String("storage", "s3", "file storage to reconcile (s3, supabase or local)")
+	verifyHashes := flags.Bool("verify-hashes", false, "read every PDF and compare its hash with the vector store")
+	ingest := flags.Bool("ingest", false, "ingest orphaned and mismatching PDFs into the vector store")
+	deleteFiles := flags.Bool("delete-files", false, "delete orphaned PDFs, their sidecars and unreferenced objects from the file storage")
+	deleteDocuments := flags.Bool("delete-documents", false, "delete documents without a PDF from the vector store")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *ingest && *deleteFiles {
+		return fmt.Errorf("-ingest and -delete-files cannot be combined")
+	}
+
+	fileStorage, err := newFileStorage(ctx, logger, *storage)
+	if err != nil {
+		return err
+	}
+
+	vectorStore := vectorstore.NewPostgresVectorStore(ctx, logger)
+	defer vectorStore.Close()
+
+	report, err := reconcile(ctx, logger, fileStorage, vectorStore, *verifyHashes)
+	if err != nil {
+		return err
+	}
+
+	for _, path := range report.orphanedFiles {
+		fmt.Printf("orphaned file: %s\n", path)
+	}
+
+	for _, path := range report.orphanedDocuments {
+		fmt.Printf("orphaned document: %s\n", path)
+	}
+
+	for _, path := range report.mismatches {
+		fmt.Printf("content hash mismatch: %s\n", path)
+	}
+
+	for path, err := range report.unreadable {
+		fmt.Printf("unreadable file: %s: %s\n", path, err)
+	}
+
+	for _, path := range report.unreferencedObjects {
+		fmt.Printf("unreferenced object: %s\n", path)
+	}
+
+	fmt.Printf("%d orphaned files, %d orphaned documents, %d mismatches, %d unreadable files, %d unreferenced objects\n", len(report.orphanedFiles), len(report.orphanedDocuments), len(report.mismatches), len(report.unreadable), len(report.unreferencedObjects))
+
+	var failed int
+
+	if *ingest {
+		runner, err := newCommandRunner()
+		if err != nil {
+			return err
+		}
+
+		pdfReader, err := newPDFReader(runner)
+		if err != nil {
+			return err
+		}
+
+		documentTimeout, err := newDocumentTimeout()
+		if err != nil {
+			return err
+		}
+
+		counter, err := newTokenCounter()
+		if err != nil {
+			return err
+		}
+
+		chunker, err := newChunker(counter)
+		if err != nil {
+			return err
+		}
+
+		embedder, closeEmbedder, err := newEmbedder(ctx, logger, counter, EMBEDDER_ALL)
+		if err != nil {
+			return err
+		}
+		defer closeEmbedder()
+
+		if err := ensureEmbeddingModel(ctx, embedder, vectorStore); err != nil {
+			return err
+		}
+
+		processor := NewProcessor(logger, nil, fileStorage, pdfReader, newOCRReader(logger, runner), chunker, embedder, vectorStore, documentTimeout)
+
+		for _, path := range append(report.orphanedFiles, report.mismatches...) {
+			if processor.BudgetExceeded() {
+				logger.Warnf("reconcile", "embedding budget exhausted, the remaining files are ingested by the next run")
+				break
+			}
+
+			data, err := fileStorage.Read(ctx, path)
+			if err == nil {
+				err = processor.ingest(ctx, "", path, data)
+			}
+
+			if err != nil {
+				logger.Errorf("reconcile", "failed ingesting '%s': %s", path, err)
+				failed++
+			}
+		}
+	}
+
+	if *deleteFiles {
+		for _, path := range report.orphanedFiles {
+			err := fileStorage.Delete(ctx, path)
+			if err == nil {
+				err = fileStorage.Delete(ctx, pdf.SidecarPath(path))
+			}
+
+			if err != nil {
+				logger.Errorf("reconcile", "failed deleting file '%s': %s", path, err)
+				failed++
+			}
+		}
+
+		// Deleting the orphaned files above only removed their index entries
+		if contentAddressed, ok := fileStorage.(*filestorage.ContentAddressedFileStorage); ok {
+			unreferenced, err := contentAddressed.UnreferencedObjects(ctx)
+			if err != nil {
+				return err
+			}
+
+			for _, path := range unreferenced {
+				if err := contentAddressed.DeleteObject(ctx, path); err != nil {
+					logger.Errorf("reconcile", "failed deleting object '%s': %s", path, err)
+					failed++
+				}
+			}
+		}
+	}
+
+	if *deleteDocuments {
+		for _, path := range report.orphanedDocuments {
+			if err := vectorStore.DeleteDocument(ctx, path); err != nil {
+				logger.Errorf("reconcile", "failed deleting document '%s': %s", path, err)
+				failed++
+			}
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d repairs failed", failed)
+	}
+
+	if len(report.unreadable) > 0 {
+		return fmt.Errorf("%d files could not be read", len(report.unreadable))
+	}
+
+	return nil
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	filestorage "github.com/JuliusMoehring/court-judgment-finder-crawler/file-storage"
+	"github.com/JuliusMoehring/court-judgment-finder-crawler/logger"
+	"github.com/JuliusMoehring/court-judgment-finder-crawler/pdf"
+	fakes "github.com/JuliusMoehring/court-judgment-finder-crawler/testing"
+	vectorstore "github.com/JuliusMoehring/court-judgment-finder-crawler/vector-store"
+)
+
+func Test_Reconcile(t *testing.T) {
+	ctx := context.Background()
+
+	const (
+		storedPath     = "judgements/bgh/2021/1_1_1.pdf"
+		changedPath    = "judgements/bgh/2021/1_1_2.pdf"
+		orphanedPath   = "judgements/bgh/2021/1_1_3.pdf"
+		unreadablePath = "judgements/bgh/2021/1_1_4.pdf"
+		deletedPath    = "judgements/bgh/2021/1_1_5.pdf"
+	)
+
+	newStores := func() (*fakes.FakeFileStorage, *fakes.FakeVectorStore) {
+		fileStorage := fakes.NewFakeFileStorage(map[string][]byte{
+			storedPath:                  []byte("stored"),
+			pdf.SidecarPath(storedPath): []byte("{}"),
+			changedPath:                 []byte("changed"),
+			orphanedPath:                []byte("orphaned"),
+			unreadablePath:              []byte("unreadable"),
+		})
+
+		vectorStore := fakes.NewFakeVectorStore(fakes.FAKE_EMBEDDING_MODEL, 8)
+
+		for path, data := range map[string]string{storedPath: "stored", changedPath: "before", unreadablePath: "unreadable", deletedPath: "deleted"} {
+			err := vectorStore.CreateDocument(ctx, vectorstore.CreateDocumentParams{FilePath: path, ContentHash: filestorage.ContentHash([]byte(data))})
+			assert.NoError(t, err, "Should create the document")
+		}
+
+		return fileStorage, vectorStore
+	}
+
+	t.Run("Reports orphaned files and documents", func(t *testing.T) {
+		fileStorage, vectorStore := newStores()
+
+		report, err := reconcile(ctx, logger.NewStdOutLogger(), fileStorage, vectorStore, false)
+
+		assert.NoError(t, err, "Should not return an error")
+		assert.Equal(t, []string{orphanedPath}, report.orphanedFiles, "Should report the PDF without a document and ignore the sidecar")
+		assert.Equal(t, []string{deletedPath}, report.orphanedDocuments, "Should report the document without a PDF")
+		assert.Empty(t, report.mismatches, "Should not compare the content hashes")
+	})
+
+	t.Run("Reports documents whose content hash does not match the PDF", func(t *testing.T) {
+		fileStorage, vectorStore := newStores()
+
+		report, err := reconcile(ctx, logger.NewStdOutLogger(), fileStorage, vectorStore, true)
+
+		assert.NoError(t, err, "Should not return an error")
+		assert.Equal(t, []string{changedPath}, report.mismatches, "Should report the changed PDF")
+		assert.Empty(t, report.unreadable, "Should read every PDF")
+	})
+
+	t.Run("Reports unreadable PDFs and continues", func(t *testing.T) {
+		fileStorage, vectorStore := newStores()
+		fileStorage.ReadErrs = map[string]error{unreadablePath: errors.New("damaged")}
+
+		report, err := reconcile(ctx, logger.NewStdOutLogger(), fileStorage, vectorStore, true)
+
+		assert.NoError(t, err, "Should not return an error")
+		assert.Contains(t, report.unreadable, unreadablePath, "Should report the unreadable PDF")
+		assert.Equal(t, []string{changedPath}, report.mismatches, "Should verify the other PDFs")
+	})
+
+	t.Run("Reports contents no path refers to anymore", func(t *testing.T) {
+		_, vectorStore := newStores()
+		fileStorage := filestorage.NewContentAddressedFileStorage(logger.NewStdOutLogger(), fakes.NewFakeFileStorage(nil))
+
+		assert.NoError(t, fileStorage.Save(ctx, []byte("before"), changedPath), "Should save the file")
+		assert.NoError(t, fileStorage.Save(ctx, []byte("changed"), changedPath), "Should replace the file")
+		assert.NoError(t, fileStorage.Save(ctx, []byte("stored"), storedPath), "Should save the file")
+		assert.NoError(t, fileStorage.Save(ctx, []byte("deleted"), deletedPath), "Should save the file")
+		assert.NoError(t, fileStorage.Delete(ctx, deletedPath), "Should delete the file")
+
+		report, err := reconcile(ctx, logger.NewStdOutLogger(), fileStorage, vectorStore, true)
+
+		assert.NoError(t, err, "Should not return an error")
+		assert.ElementsMatch(t, []string{
+			filestorage.ContentAddressedPath(filestorage.ContentHash([]byte("before")), changedPath),
+			filestorage.ContentAddressedPath(filestorage.ContentHash([]byte("deleted")), deletedPath),
+		}, report.unreferencedObjects, "Should report the replaced and the deleted content")
+		assert.Equal(t, []string{changedPath}, report.mismatches, "Should read the files through the index")
+	})
+
+	t.Run("Returns errors of the vector store", func(t *testing.T) {
+		fileStorage, vectorStore := newStores()
+		vectorStore.Err = errors.New("unavailable")
+
+		_, err := reconcile(ctx, logger.NewStdOutLogger(), fileStorage, vectorStore, false)
+
+		assert.Error(t, err, "Should return the error")
+	})
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/JuliusMoehring/court-judgment-finder-crawler/embedder"
+	"github.com/JuliusMoehring/court-judgment-finder-crawler/logger"
+	vectorstore "github.com/JuliusMoehring/court-judgment-finder-crawler/vector-store"
+)
+
+const DEFAULT_REEMBED_BATCH_SIZE = 1000
+
+// Embeds all stored chunks with the model and stages the embeddings until every chunk is embedded. Staged
+// embeddings survive failures, so the next run continues where the last one stopped.
+func reembed(ctx context.Context, logger logger.Logger, e embedder.Embedder, vectorStore vectorstore.VectorStore, batchSize int) (int, error) {
+	total := 0
+
+	for {
+		chunks, err := vectorStore.ListChunksToReembed(ctx, vectorstore.ListChunksToReembedParams{
+			Model:      e.Model(),
+			Dimensions: e.Dimensions(),
+			Limit:      batchSize,
+		})
+		if err != nil {
+			return total, err
+		}
+
+		if len(chunks) == 0 {
+			return total, nil
+		}
+
+		texts := make([]string, len(chunks))
+
+		for i, chunk := range chunks {
+			texts[i] = chunk.Text
+		}
+
+		embeddings, err := e.EmbedBatch(ctx, texts)
+		if err != nil {
+			return total, err
+		}
+
+		params := vectorstore.StageChunkEmbeddingsParams{
+			Model:      e.Model(),
+			Dimensions: e.Dimensions(),
+			Embeddings: make(map[string][]float32, len(chunks)),
+		}
+
+		for i, chunk := range chunks {
+			params.Embeddings[chunk.ID] = embeddings[i]
+		}
+
+		if err := vectorStore.StageChunkEmbeddings(ctx, params); err != nil {
+			return total, err
+		}
+
+		total += len(chunks)
+		logger.Infof("reembed", "embedded %d chunks with %s", total, e.Model())
+	}
+}
+
+// Embeds the stored chunks with the configured model and switches the vector store to it once all chunks are
+// embedded, without crawling or downloading the documents again
+func runReembed(ctx context.Context, logger logger.Logger, args []string) error {
+	flags := flag.NewFlagSet("reembed", flag.ExitOnError)
+
+	batchSize := flags.Int("batch-size", DEFAULT_REEMBED_BATCH_SIZE, "number of chunks embedded and staged at once")
+	attempts := flags.Int("attempts", 3, "number of times chunks created during the run are embedded before giving up")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *batchSize <= 0 || *attempts <= 0 {
+		return fmt.Errorf("usage: reembed [-batch-size <chunks>] [-attempts <attempts>]")
+	}
+
+	counter, err := newTokenCounter()
+	if err != nil {
+		return err
+	}
+	embedder, closeEmbedder, err := newEmbedder(ctx, logger, counter, EMBEDDER_ALL)
+	if err != nil {
+		return err
+	}
+	defer closeEmbedder()
+	vectorStore := vectorstore.NewPostgresVectorStore(ctx, logger)
+	defer vectorStore.Close()
+
+	logger.Infof("reembed", "re-embedding chunks with %s and %d dimensions", embedder.Model(), embedder.Dimensions())
+
+	// Crawls running at the same time create chunks with the previous model, those are embedded in another attempt
+	for attempt := 1; ; attempt++ {
+		embedded, err := reembed(ctx, logger, embedder, vectorStore, *batchSize)
+		if err != nil {
+			return fmt.Errorf("re-embedding stopped after %d chunks, run again to continue: %w", embedded, err)
+		}
+
+		err = vectorStore.SwitchEmbeddingModel(ctx, embedder.Model(), embedder.Dimensions())
+		if errors.Is(err, vectorstore.ErrReembeddingIncomplete) && attempt < *attempts {
+			logger.Warnf("reembed", "chunks were created while re-embedding: %s", err)
+			continue
+		}
+
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("switched to %s with %d dimensions\n", embedder.Model(), embedder.Dimensions())
+
+		return nil
+	}
+}
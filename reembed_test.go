@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/JuliusMoehring/court-judgment-finder-crawler/logger"
+	fakes "github.com/JuliusMoehring/court-judgment-finder-crawler/testing"
+	vectorstore "github.com/JuliusMoehring/court-judgment-finder-crawler/vector-store"
+)
+
+func Test_reembed(t *testing.T) {
+	ctx := context.Background()
+
+	newStore := func() *fakes.FakeVectorStore {
+		store := fakes.NewFakeVectorStore("old-model", 4)
+
+		for _, path := range []string{"judgements/a.pdf", "judgements/b.pdf"} {
+			store.CreateDocument(ctx, vectorstore.CreateDocumentParams{
+				FilePath:            path,
+				EmbeddingModel:      "old-model",
+				EmbeddingDimensions: 4,
+				Chunks: []vectorstore.CreateDocumentParamsChunk{
+					{Text: path + " eins", Embedding: []float32{1, 0, 0, 0}},
+					{Text: path + " zwei", Embedding: []float32{0, 1, 0, 0}},
+				},
+			})
+		}
+
+		return store
+	}
+
+	t.Run("Embeds all chunks in batches and switches the model", func(t *testing.T) {
+		store := newStore()
+		embedder := fakes.NewFakeEmbedder(8)
+
+		embedded, err := reembed(ctx, logger.NewStdOutLogger(), embedder, store, 3)
+		assert.NoError(t, err, "Should embed the chunks")
+		assert.Equal(t, 4, embedded, "Should embed every chunk")
+
+		assert.NoError(t, store.SwitchEmbeddingModel(ctx, fakes.FAKE_EMBEDDING_MODEL, 8), "Should switch the model")
+
+		model, dimensions := store.EmbeddingModel()
+		assert.Equal(t, fakes.FAKE_EMBEDDING_MODEL, model, "Should activate the model")
+		assert.Equal(t, 8, dimensions, "Should activate the dimensions")
+
+		document, _ := store.Document("judgements/a.pdf")
+		assert.Equal(t, fakes.FakeEmbedding("judgements/a.pdf zwei", 8), document.Chunks[1].Embedding, "Should replace the embeddings")
+	})
+
+	t.Run("Continues with the chunks that are not staged yet", func(t *testing.T) {
+		store := newStore()
+		embedder := fakes.NewFakeEmbedder(8)
+
+		_, err := reembed(ctx, logger.NewStdOutLogger(), embedder, store, 3)
+		assert.NoError(t, err, "Should embed the chunks")
+
+		embedded, err := reembed(ctx, logger.NewStdOutLogger(), embedder, store, 3)
+		assert.NoError(t, err, "Should not fail")
+		assert.Equal(t, 0, embedded, "Should not embed staged chunks again")
+		assert.Len(t, embedder.Texts(), 4, "Should embed every chunk once")
+	})
+
+	t.Run("Does not switch before all chunks are embedded", func(t *testing.T) {
+		store := newStore()
+
+		err := store.SwitchEmbeddingModel(ctx, fakes.FAKE_EMBEDDING_MODEL, 8)
+
+		assert.ErrorIs(t, err, vectorstore.ErrReembeddingIncomplete, "Should refuse to switch")
+
+		model, _ := store.EmbeddingModel()
+		assert.Equal(t, "old-model", model, "Should keep the model")
+	})
+}
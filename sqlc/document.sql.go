@@ -12,15 +12,46 @@ import (
 )
 
 const createDocument = `-- name: CreateDocument :one
-INSERT INTO documents (file_path)
-VALUES ($1)
+INSERT INTO documents (file_path, content_hash, page_count, title, producer, pdf_created_at, pdf_modified_at,
+                       encrypted, file_size)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 ON CONFLICT (file_path) DO UPDATE
-    SET updated_at = CURRENT_TIMESTAMP
+    SET content_hash    = $2,
+        page_count      = $3,
+        title           = $4,
+        producer        = $5,
+        pdf_created_at  = $6,
+        pdf_modified_at = $7,
+        encrypted       = $8,
+        file_size       = $9,
+        updated_at      = CURRENT_TIMESTAMP
 RETURNING id
 `
 
-func (q *Queries) CreateDocument(ctx context.Context, filePath string) (pgtype.UUID, error) {
-	row := q.db.QueryRow(ctx, createDocument, filePath)
+type CreateDocumentParams struct {
+	FilePath      string
+	ContentHash   pgtype.Text
+	PageCount     pgtype.Int4
+	Title         pgtype.Text
+	Producer      pgtype.Text
+	PdfCreatedAt  pgtype.Timestamptz
+	PdfModifiedAt pgtype.Timestamptz
+	Encrypted     pgtype.Bool
+	FileSize      pgtype.Int8
+}
+
+func (q *Queries) CreateDocument(ctx context.Context, arg CreateDocumentParams) (pgtype.UUID, error) {
+	row := q.db.QueryRow(ctx, createDocument,
+		arg.FilePath,
+		arg.ContentHash,
+		arg.PageCount,
+		arg.Title,
+		arg.Producer,
+		arg.PdfCreatedAt,
+		arg.PdfModifiedAt,
+		arg.Encrypted,
+		arg.FileSize,
+	)
 	var id pgtype.UUID
 	err := row.Scan(&id)
 	return id, err
@@ -38,3 +69,96 @@ func (q *Queries) GetDocumentIDByFilePath(ctx context.Context, filePath string)
 	err := row.Scan(&id)
 	return id, err
 }
+
+const listDocuments = `-- name: ListDocuments :many
+SELECT id, file_path, content_hash
+FROM documents
+ORDER BY file_path
+`
+
+type ListDocumentsRow struct {
+	ID          pgtype.UUID
+	FilePath    string
+	ContentHash pgtype.Text
+}
+
+func (q *Queries) ListDocuments(ctx context.Context) ([]ListDocumentsRow, error) {
+	rows, err := q.db.Query(ctx, listDocuments)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListDocumentsRow
+	for rows.Next() {
+		var i ListDocumentsRow
+		if err := rows.Scan(&i.ID, &i.FilePath, &i.ContentHash); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const deleteDocument = `-- name: DeleteDocument :exec
+DELETE
+FROM documents
+WHERE id = $1
+`
+
+func (q *Queries) DeleteDocument(ctx context.Context, id pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, deleteDocument, id)
+	return err
+}
+
+const updateDocumentJudgementMetadata = `-- name: UpdateDocumentJudgementMetadata :exec
+UPDATE documents
+SET file_number         = $2,
+    decision_date       = $3,
+    decision_type       = $4,
+    court               = $5,
+    senate              = $6,
+    ecli                = $7,
+    nachschlagewerk     = $8,
+    bghz                = $9,
+    bghr                = $10,
+    norms               = $11,
+    metadata_mismatches = $12,
+    updated_at          = CURRENT_TIMESTAMP
+WHERE id = $1
+`
+
+type UpdateDocumentJudgementMetadataParams struct {
+	ID                 pgtype.UUID
+	FileNumber         pgtype.Text
+	DecisionDate       pgtype.Date
+	DecisionType       pgtype.Text
+	Court              pgtype.Text
+	Senate             pgtype.Text
+	Ecli               pgtype.Text
+	Nachschlagewerk    pgtype.Bool
+	Bghz               pgtype.Bool
+	Bghr               pgtype.Bool
+	Norms              []string
+	MetadataMismatches []string
+}
+
+func (q *Queries) UpdateDocumentJudgementMetadata(ctx context.Context, arg UpdateDocumentJudgementMetadataParams) error {
+	_, err := q.db.Exec(ctx, updateDocumentJudgementMetadata,
+		arg.ID,
+		arg.FileNumber,
+		arg.DecisionDate,
+		arg.DecisionType,
+		arg.Court,
+		arg.Senate,
+		arg.Ecli,
+		arg.Nachschlagewerk,
+		arg.Bghz,
+		arg.Bghr,
+		arg.Norms,
+		arg.MetadataMismatches,
+	)
+	return err
+}
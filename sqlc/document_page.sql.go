@@ -9,34 +9,49 @@ import (
 	"context"
 
 	"github.com/jackc/pgx/v5/pgtype"
-	"github.com/pgvector/pgvector-go"
 )
 
 const createDocumentPage = `-- name: CreateDocumentPage :one
-INSERT INTO document_pages (page, text, embeddings, document_id)
-VALUES ($1, $2, $3, $4)
+INSERT INTO document_pages (page, text, document_id, extraction_method, section_types)
+VALUES ($1, $2, $3, $4, $5)
 ON CONFLICT (document_id, page) DO UPDATE
-    SET document_id = $4,
-        page        = $1,
-        updated_at  = CURRENT_TIMESTAMP
+    SET document_id       = $3,
+        page              = $1,
+        text              = $2,
+        extraction_method = $4,
+        section_types     = $5,
+        updated_at        = CURRENT_TIMESTAMP
 RETURNING id
 `
 
 type CreateDocumentPageParams struct {
-	Page       int32
-	Text       string
-	Embeddings pgvector.Vector
-	DocumentID pgtype.UUID
+	Page             int32
+	Text             string
+	DocumentID       pgtype.UUID
+	ExtractionMethod string
+	SectionTypes     []string
 }
 
 func (q *Queries) CreateDocumentPage(ctx context.Context, arg CreateDocumentPageParams) (pgtype.UUID, error) {
 	row := q.db.QueryRow(ctx, createDocumentPage,
 		arg.Page,
 		arg.Text,
-		arg.Embeddings,
 		arg.DocumentID,
+		arg.ExtractionMethod,
+		arg.SectionTypes,
 	)
 	var id pgtype.UUID
 	err := row.Scan(&id)
 	return id, err
 }
+
+const deleteDocumentPages = `-- name: DeleteDocumentPages :exec
+DELETE
+FROM document_pages
+WHERE document_id = $1
+`
+
+func (q *Queries) DeleteDocumentPages(ctx context.Context, documentID pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, deleteDocumentPages, documentID)
+	return err
+}
@@ -9,19 +9,129 @@ import (
 	"github.com/pgvector/pgvector-go"
 )
 
+type Chunk struct {
+	ID                  pgtype.UUID
+	DocumentID          pgtype.UUID
+	Position            int32
+	Text                string
+	Embeddings          pgvector.Vector
+	Strategy            string
+	StartOffset         int32
+	EndOffset           int32
+	StartPage           int32
+	EndPage             int32
+	StartMarginNumber   pgtype.Int4
+	EndMarginNumber     pgtype.Int4
+	SectionTypes        []string
+	CreatedAt           pgtype.Timestamptz
+	UpdatedAt           pgtype.Timestamptz
+	EmbeddingModel      string
+	EmbeddingDimensions int32
+}
+
+type ChunkEmbedding struct {
+	ChunkID             pgtype.UUID
+	EmbeddingModel      string
+	EmbeddingDimensions int32
+	Embedding           pgvector.Vector
+	CreatedAt           pgtype.Timestamptz
+}
+
+type Citation struct {
+	ID              pgtype.UUID
+	DocumentID      pgtype.UUID
+	CitedDocumentID pgtype.UUID
+	Court           string
+	DecisionType    pgtype.Text
+	DecisionDate    pgtype.Date
+	FileNumber      string
+	Page            int32
+	Text            string
+	CreatedAt       pgtype.Timestamptz
+	UpdatedAt       pgtype.Timestamptz
+}
+
 type Document struct {
-	ID        pgtype.UUID
-	FilePath  string
-	CreatedAt pgtype.Timestamptz
-	UpdatedAt pgtype.Timestamptz
+	ID                 pgtype.UUID
+	FilePath           string
+	CreatedAt          pgtype.Timestamptz
+	UpdatedAt          pgtype.Timestamptz
+	ContentHash        pgtype.Text
+	PageCount          pgtype.Int4
+	Title              pgtype.Text
+	Producer           pgtype.Text
+	PdfCreatedAt       pgtype.Timestamptz
+	PdfModifiedAt      pgtype.Timestamptz
+	Encrypted          pgtype.Bool
+	FileSize           pgtype.Int8
+	FileNumber         pgtype.Text
+	DecisionDate       pgtype.Date
+	DecisionType       pgtype.Text
+	Court              pgtype.Text
+	Senate             pgtype.Text
+	Ecli               pgtype.Text
+	Nachschlagewerk    pgtype.Bool
+	Bghz               pgtype.Bool
+	Bghr               pgtype.Bool
+	Norms              []string
+	MetadataMismatches []string
 }
 
 type DocumentPage struct {
+	ID               pgtype.UUID
+	Page             int32
+	Text             string
+	Embeddings       pgvector.Vector
+	DocumentID       pgtype.UUID
+	CreatedAt        pgtype.Timestamptz
+	UpdatedAt        pgtype.Timestamptz
+	ExtractionMethod string
+	SectionTypes     []string
+}
+
+type DocumentSection struct {
+	ID                pgtype.UUID
+	DocumentID        pgtype.UUID
+	Position          int32
+	Type              string
+	Text              string
+	StartPage         int32
+	EndPage           int32
+	StartMarginNumber pgtype.Int4
+	EndMarginNumber   pgtype.Int4
+	CreatedAt         pgtype.Timestamptz
+	UpdatedAt         pgtype.Timestamptz
+}
+
+type EmbeddingCache struct {
+	Model      string
+	Dimensions int32
+	TextHash   string
+	Embedding  pgvector.Vector
+	CreatedAt  pgtype.Timestamptz
+	LastUsedAt pgtype.Timestamptz
+	Policy     string
+}
+
+type EmbeddingModel struct {
+	Model      string
+	Dimensions int32
+	Active     bool
+	CreatedAt  pgtype.Timestamptz
+	UpdatedAt  pgtype.Timestamptz
+}
+
+type StatuteReference struct {
 	ID         pgtype.UUID
-	Page       int32
-	Text       string
-	Embeddings pgvector.Vector
 	DocumentID pgtype.UUID
+	Page       int32
+	Law        string
+	Kind       string
+	Section    string
+	Paragraph  pgtype.Int4
+	Sentence   pgtype.Int4
+	Number     pgtype.Int4
+	Reference  string
 	CreatedAt  pgtype.Timestamptz
 	UpdatedAt  pgtype.Timestamptz
 }
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/JuliusMoehring/court-judgment-finder-crawler/judgement"
+	"github.com/JuliusMoehring/court-judgment-finder-crawler/logger"
+	vectorstore "github.com/JuliusMoehring/court-judgment-finder-crawler/vector-store"
+)
+
+// Prints all documents referencing a statute, e.g. "§ 5 UWG" or "§ 5 Abs. 1 Satz 2 UWG"
+func runStatute(ctx context.Context, logger logger.Logger, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: statute \"§ 5 UWG\"")
+	}
+
+	references := judgement.ParseStatuteReferences(args[0])
+	if len(references) != 1 {
+		return fmt.Errorf("expected a single statute reference, found %d in '%s'", len(references), args[0])
+	}
+
+	reference := references[0]
+
+	vectorStore := vectorstore.NewPostgresVectorStore(ctx, logger)
+	defer vectorStore.Close()
+
+	documents, err := vectorStore.ListDocumentsByStatute(ctx, vectorstore.ListDocumentsByStatuteParams{
+		Law:       reference.Law,
+		Kind:      reference.Kind,
+		Section:   reference.Section,
+		Paragraph: reference.Paragraph,
+		Sentence:  reference.Sentence,
+		Number:    reference.Number,
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, document := range documents {
+		fmt.Println(document.FilePath)
+	}
+
+	fmt.Printf("%d documents reference %s\n", len(documents), reference)
+
+	return nil
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	filestorage "github.com/JuliusMoehring/court-judgment-finder-crawler/file-storage"
+	"github.com/JuliusMoehring/court-judgment-finder-crawler/logger"
+)
+
+const (
+	DEFAULT_BUCKET            = "court-judgement-finder"
+	DEFAULT_LOCAL_STORAGE_DIR = "storage"
+)
+
+// Creates the file storage for the given backend name (s3, supabase or local)
+func newFileStorage(ctx context.Context, logger logger.Logger, backend string) (filestorage.FileStorage, error) {
+	return newFileStorageWithKey(ctx, logger, backend, os.Getenv("FILE_STORAGE_ENCRYPTION_KEY_ID"))
+}
+
+// Creates the file storage for the given backend name, files are encrypted with the key of the given id from
+// FILE_STORAGE_ENCRYPTION_KEYS unless the id is empty
+func newFileStorageWithKey(ctx context.Context, logger logger.Logger, backend string, keyID string) (filestorage.FileStorage, error) {
+	fileStorage, err := newObjectStorageWithKey(ctx, logger, backend, keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The content hash is calculated before encryption, so identical documents are still stored once
+	if os.Getenv("FILE_STORAGE_LAYOUT") == "content-addressed" {
+		fileStorage = filestorage.NewContentAddressedFileStorage(logger, fileStorage)
+	}
+
+	return fileStorage, nil
+}
+
+// Like newFileStorageWithKey but without the layout of FILE_STORAGE_LAYOUT, so the stored objects are listed and
+// written as they are, including the index and contents of the content addressed layout
+func newObjectStorageWithKey(ctx context.Context, logger logger.Logger, backend string, keyID string) (filestorage.FileStorage, error) {
+	var fileStorage filestorage.FileStorage
+
+	switch backend {
+	case "s3":
+		fileStorage = filestorage.NewS3FileStorage(ctx, logger, DEFAULT_BUCKET)
+	case "supabase":
+		fileStorage = filestorage.NewSupabaseFileStorage(logger, DEFAULT_BUCKET)
+	case "local":
+		dir := os.Getenv("LOCAL_FILE_STORAGE_DIR")
+		if dir == "" {
+			dir = DEFAULT_LOCAL_STORAGE_DIR
+		}
+
+		fileStorage = filestorage.NewLocalFileStorage(dir)
+	default:
+		return nil, fmt.Errorf("unknown file storage backend: '%s'", backend)
+	}
+
+	if keyID != "" {
+		keys, err := filestorage.ParseEncryptionKeys(os.Getenv("FILE_STORAGE_ENCRYPTION_KEYS"))
+		if err != nil {
+			return nil, err
+		}
+
+		fileStorage, err = filestorage.NewEncryptedFileStorage(logger, fileStorage, keyID, keys)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return fileStorage, nil
+}
@@ -0,0 +1,53 @@
+package testing
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+var ErrNotFound = errors.New("not found")
+
+// Returns the data registered for a URL and ErrNotFound for unknown URLs
+type FakeDownloader struct {
+	mutex     sync.Mutex
+	files     map[string][]byte
+	downloads []string
+
+	// Returned by every call instead of the data if set
+	Err error
+}
+
+func NewFakeDownloader(files map[string][]byte) *FakeDownloader {
+	if files == nil {
+		files = map[string][]byte{}
+	}
+
+	return &FakeDownloader{files: files}
+}
+
+func (d *FakeDownloader) Download(ctx context.Context, url string) ([]byte, error) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	d.downloads = append(d.downloads, url)
+
+	if d.Err != nil {
+		return nil, d.Err
+	}
+
+	data, ok := d.files[url]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	return data, nil
+}
+
+// Returns the URLs of all downloads in the order they were requested
+func (d *FakeDownloader) Downloads() []string {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	return append([]string{}, d.downloads...)
+}
@@ -0,0 +1,98 @@
+// Package testing provides deterministic in-memory fakes of the interfaces the crawler depends on
+package testing
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"math"
+	"sync"
+)
+
+const FAKE_EMBEDDING_MODEL = "fake-embedding"
+
+// Returns a unit vector derived from the SHA-256 hash of the text, equal texts always have equal embeddings
+func FakeEmbedding(text string, dimensions int) []float32 {
+	embedding := make([]float32, dimensions)
+	seed := sha256.Sum256([]byte(text))
+
+	var block [sha256.Size]byte
+	norm := 0.0
+
+	for i := range embedding {
+		// Every block of the hash yields eight dimensions
+		if i%8 == 0 {
+			block = sha256.Sum256(binary.BigEndian.AppendUint32(seed[:], uint32(i/8)))
+		}
+
+		value := float64(binary.BigEndian.Uint32(block[i%8*4:]))/math.MaxUint32*2 - 1
+		embedding[i] = float32(value)
+		norm += value * value
+	}
+
+	norm = math.Sqrt(norm)
+
+	for i := range embedding {
+		embedding[i] = float32(float64(embedding[i]) / norm)
+	}
+
+	return embedding
+}
+
+// Embeds texts with FakeEmbedding and records them
+type FakeEmbedder struct {
+	mutex      sync.Mutex
+	dimensions int
+	texts      []string
+
+	// Returned by every call instead of embeddings if set
+	Err error
+}
+
+func NewFakeEmbedder(dimensions int) *FakeEmbedder {
+	return &FakeEmbedder{dimensions: dimensions}
+}
+
+func (e *FakeEmbedder) Embed(ctx context.Context, text string) ([]float32, error) {
+	embeddings, err := e.EmbedBatch(ctx, []string{text})
+	if err != nil {
+		return nil, err
+	}
+
+	return embeddings[0], nil
+}
+
+func (e *FakeEmbedder) EmbedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if e.Err != nil {
+		return nil, e.Err
+	}
+
+	e.texts = append(e.texts, texts...)
+
+	embeddings := make([][]float32, len(texts))
+
+	for i, text := range texts {
+		embeddings[i] = FakeEmbedding(text, e.dimensions)
+	}
+
+	return embeddings, nil
+}
+
+func (e *FakeEmbedder) Model() string {
+	return FAKE_EMBEDDING_MODEL
+}
+
+func (e *FakeEmbedder) Dimensions() int {
+	return e.dimensions
+}
+
+// Returns all embedded texts in the order they were embedded
+func (e *FakeEmbedder) Texts() []string {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	return append([]string{}, e.texts...)
+}
@@ -0,0 +1,40 @@
+package testing
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_FakeEmbedding(t *testing.T) {
+	t.Run("Returns equal embeddings for equal texts", func(t *testing.T) {
+		assert.Equal(t, FakeEmbedding("Die Revision hat Erfolg.", 16), FakeEmbedding("Die Revision hat Erfolg.", 16), "Should be deterministic")
+		assert.NotEqual(t, FakeEmbedding("Die Revision hat Erfolg.", 16), FakeEmbedding("Die Revision ist unbegründet.", 16), "Should differ for other texts")
+	})
+
+	t.Run("Returns unit vectors with the dimensions", func(t *testing.T) {
+		embedding := FakeEmbedding("Die Revision hat Erfolg.", 1536)
+
+		norm := 0.0
+		for _, value := range embedding {
+			norm += float64(value) * float64(value)
+		}
+
+		assert.Len(t, embedding, 1536, "Should have the dimensions")
+		assert.InDelta(t, 1, math.Sqrt(norm), 1e-4, "Should be normalized")
+	})
+}
+
+func Test_FakeEmbedder(t *testing.T) {
+	t.Run("Records the embedded texts", func(t *testing.T) {
+		embedder := NewFakeEmbedder(4)
+
+		embeddings, err := embedder.EmbedBatch(context.Background(), []string{"eins", "zwei"})
+
+		assert.NoError(t, err, "Should embed the texts")
+		assert.Equal(t, [][]float32{FakeEmbedding("eins", 4), FakeEmbedding("zwei", 4)}, embeddings, "Should return the fake embeddings")
+		assert.Equal(t, []string{"eins", "zwei"}, embedder.Texts(), "Should record the texts")
+	})
+}
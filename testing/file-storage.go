@@ -0,0 +1,115 @@
+package testing
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+
+	filestorage "github.com/JuliusMoehring/court-judgment-finder-crawler/file-storage"
+)
+
+// Keeps the files in memory
+type FakeFileStorage struct {
+	mutex sync.Mutex
+	files map[string][]byte
+
+	// Returned by every call if set
+	Err error
+	// Returned by Read for the path if set
+	ReadErrs map[string]error
+}
+
+func NewFakeFileStorage(files map[string][]byte) *FakeFileStorage {
+	storage := &FakeFileStorage{files: map[string][]byte{}}
+
+	for path, data := range files {
+		storage.files[path] = append([]byte{}, data...)
+	}
+
+	return storage
+}
+
+func (s *FakeFileStorage) Delete(ctx context.Context, path string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.Err != nil {
+		return s.Err
+	}
+
+	if _, ok := s.files[path]; !ok {
+		return filestorage.ErrFileNotFound
+	}
+
+	delete(s.files, path)
+
+	return nil
+}
+
+func (s *FakeFileStorage) Exists(ctx context.Context, path string) (bool, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.Err != nil {
+		return false, s.Err
+	}
+
+	_, ok := s.files[path]
+
+	return ok, nil
+}
+
+func (s *FakeFileStorage) List(ctx context.Context, prefix string) ([]string, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.Err != nil {
+		return nil, s.Err
+	}
+
+	paths := []string{}
+
+	for path := range s.files {
+		if strings.HasPrefix(path, prefix) {
+			paths = append(paths, path)
+		}
+	}
+
+	sort.Strings(paths)
+
+	return paths, nil
+}
+
+func (s *FakeFileStorage) Read(ctx context.Context, path string) ([]byte, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.Err != nil {
+		return nil, s.Err
+	}
+
+	if err := s.ReadErrs[path]; err != nil {
+		return nil, err
+	}
+
+	data, ok := s.files[path]
+	if !ok {
+		return nil, filestorage.ErrFileNotFound
+	}
+
+	return append([]byte{}, data...), nil
+}
+
+func (s *FakeFileStorage) Save(ctx context.Context, data []byte, path string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.Err != nil {
+		return s.Err
+	}
+
+	s.files[path] = append([]byte{}, data...)
+
+	return nil
+}
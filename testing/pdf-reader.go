@@ -0,0 +1,79 @@
+package testing
+
+import (
+	"context"
+	"io"
+	"strings"
+
+	"github.com/JuliusMoehring/court-judgment-finder-crawler/pdf"
+)
+
+const FAKE_PDF_READER_VERSION = "fake-pdf-reader 1.0"
+
+// Reads "PDFs" that are their text, pages are terminated by form feeds like pdftotext terminates them
+type FakePDFReader struct {
+	// Returned by Read if set
+	ReadErr error
+	// Returned by Metadata if set
+	MetadataErr error
+	// Added to the page count of the metadata, e.g. to fail the page count check
+	ExtraPages int
+}
+
+// Returns a fake PDF consisting of the pages
+func FakePDF(pages ...string) []byte {
+	var builder strings.Builder
+
+	for _, page := range pages {
+		builder.WriteString(page)
+		builder.WriteString("\f")
+	}
+
+	return []byte(builder.String())
+}
+
+func (r *FakePDFReader) Read(ctx context.Context, reader io.Reader) ([]byte, error) {
+	if r.ReadErr != nil {
+		return nil, r.ReadErr
+	}
+
+	return io.ReadAll(reader)
+}
+
+func (r *FakePDFReader) Metadata(ctx context.Context, reader io.Reader) (*pdf.Metadata, error) {
+	if r.MetadataErr != nil {
+		return nil, r.MetadataErr
+	}
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pdf.Metadata{
+		Pages:    strings.Count(string(data), "\f") + r.ExtraPages,
+		Producer: FAKE_PDF_READER_VERSION,
+	}, nil
+}
+
+func (r *FakePDFReader) Version() string {
+	return FAKE_PDF_READER_VERSION
+}
+
+// Returns the registered text of a page, pages without text fail
+type FakeOCRReader struct {
+	Pages map[int]string
+}
+
+func (r *FakeOCRReader) ReadPage(ctx context.Context, data []byte, page int) ([]byte, error) {
+	text, ok := r.Pages[page]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	return []byte(text), nil
+}
+
+func (r *FakeOCRReader) Version() string {
+	return "fake-ocr-reader 1.0"
+}
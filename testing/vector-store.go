@@ -0,0 +1,427 @@
+package testing
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	vectorstore "github.com/JuliusMoehring/court-judgment-finder-crawler/vector-store"
+)
+
+type fakeDocument struct {
+	id     string
+	params vectorstore.CreateDocumentParams
+	// Staged embeddings by position of the chunk
+	staged map[int]stagedEmbedding
+}
+
+type stagedEmbedding struct {
+	model      string
+	dimensions int
+	embedding  []float32
+}
+
+// Keeps the documents in memory. Citations are resolved when they are listed, chunk IDs are the document ID and
+// the position of the chunk separated by a slash.
+type FakeVectorStore struct {
+	mutex      sync.Mutex
+	documents  map[string]*fakeDocument
+	nextID     int
+	model      string
+	dimensions int
+
+	// Returned by every call except Close if set
+	Err error
+}
+
+// The store starts with the embedding model and dimensions as active model
+func NewFakeVectorStore(model string, dimensions int) *FakeVectorStore {
+	return &FakeVectorStore{
+		documents:  map[string]*fakeDocument{},
+		model:      model,
+		dimensions: dimensions,
+	}
+}
+
+func (v *FakeVectorStore) Close() {}
+
+func (v *FakeVectorStore) EnsureEmbeddingModel(ctx context.Context, model string, dimensions int) error {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	if v.Err != nil {
+		return v.Err
+	}
+
+	if v.model == model && v.dimensions == dimensions {
+		return nil
+	}
+
+	for _, document := range v.documents {
+		if len(document.params.Chunks) > 0 {
+			return fmt.Errorf("%w: configured %s with %d dimensions, stored %s with %d dimensions", vectorstore.ErrEmbeddingModelMismatch, model, dimensions, v.model, v.dimensions)
+		}
+	}
+
+	v.model, v.dimensions = model, dimensions
+
+	return nil
+}
+
+// Returns the active embedding model and its dimensions
+func (v *FakeVectorStore) EmbeddingModel() (string, int) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	return v.model, v.dimensions
+}
+
+func (v *FakeVectorStore) CreateDocument(ctx context.Context, params vectorstore.CreateDocumentParams) error {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	if v.Err != nil {
+		return v.Err
+	}
+
+	if len(params.Chunks) > 0 && (params.EmbeddingModel != v.model || params.EmbeddingDimensions != v.dimensions) {
+		return fmt.Errorf("%w: embedded with %s with %d dimensions, stored %s with %d dimensions", vectorstore.ErrEmbeddingModelMismatch, params.EmbeddingModel, params.EmbeddingDimensions, v.model, v.dimensions)
+	}
+
+	// Like the real stores, creating an existing document replaces it and keeps its ID
+	document, ok := v.documents[params.FilePath]
+	if !ok {
+		v.nextID++
+		document = &fakeDocument{id: fmt.Sprintf("document-%d", v.nextID)}
+		v.documents[params.FilePath] = document
+	}
+
+	document.params = params
+	document.staged = map[int]stagedEmbedding{}
+
+	return nil
+}
+
+// Returns the parameters the document was created with
+func (v *FakeVectorStore) Document(path string) (vectorstore.CreateDocumentParams, bool) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	document, ok := v.documents[path]
+	if !ok {
+		return vectorstore.CreateDocumentParams{}, false
+	}
+
+	return document.params, true
+}
+
+func (v *FakeVectorStore) GetDocumentIDByFilePath(ctx context.Context, path string) (string, error) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	if v.Err != nil {
+		return "", v.Err
+	}
+
+	document, ok := v.documents[path]
+	if !ok {
+		return "", vectorstore.ErrDocumentNotFound
+	}
+
+	return document.id, nil
+}
+
+func (v *FakeVectorStore) ListDocuments(ctx context.Context) ([]vectorstore.Document, error) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	if v.Err != nil {
+		return nil, v.Err
+	}
+
+	documents := []vectorstore.Document{}
+
+	for _, path := range v.paths() {
+		documents = append(documents, v.document(path))
+	}
+
+	return documents, nil
+}
+
+func (v *FakeVectorStore) DeleteDocument(ctx context.Context, path string) error {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	if v.Err != nil {
+		return v.Err
+	}
+
+	if _, ok := v.documents[path]; !ok {
+		return vectorstore.ErrDocumentNotFound
+	}
+
+	delete(v.documents, path)
+
+	return nil
+}
+
+func (v *FakeVectorStore) ListCitations(ctx context.Context, path string) ([]vectorstore.Citation, error) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	if v.Err != nil {
+		return nil, v.Err
+	}
+
+	document, ok := v.documents[path]
+	if !ok {
+		return []vectorstore.Citation{}, nil
+	}
+
+	citations := v.citations(path, document)
+
+	sort.SliceStable(citations, func(i, j int) bool {
+		if citations[i].Page != citations[j].Page {
+			return citations[i].Page < citations[j].Page
+		}
+
+		return citations[i].FileNumber < citations[j].FileNumber
+	})
+
+	return citations, nil
+}
+
+func (v *FakeVectorStore) ListCitedBy(ctx context.Context, path string) ([]vectorstore.Citation, error) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	if v.Err != nil {
+		return nil, v.Err
+	}
+
+	citations := []vectorstore.Citation{}
+
+	for _, citingPath := range v.paths() {
+		for _, citation := range v.citations(citingPath, v.documents[citingPath]) {
+			if citation.CitedFilePath == path {
+				citations = append(citations, citation)
+			}
+		}
+	}
+
+	return citations, nil
+}
+
+func (v *FakeVectorStore) ListDocumentsByStatute(ctx context.Context, params vectorstore.ListDocumentsByStatuteParams) ([]vectorstore.Document, error) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	if v.Err != nil {
+		return nil, v.Err
+	}
+
+	documents := []vectorstore.Document{}
+
+	for _, path := range v.paths() {
+		for _, reference := range v.documents[path].params.StatuteReferences {
+			if reference.Law != params.Law || reference.Kind != params.Kind || reference.Section != params.Section {
+				continue
+			}
+
+			if (params.Paragraph != 0 && reference.Paragraph != params.Paragraph) ||
+				(params.Sentence != 0 && reference.Sentence != params.Sentence) ||
+				(params.Number != 0 && reference.Number != params.Number) {
+				continue
+			}
+
+			documents = append(documents, v.document(path))
+			break
+		}
+	}
+
+	return documents, nil
+}
+
+func (v *FakeVectorStore) ListChunksToReembed(ctx context.Context, params vectorstore.ListChunksToReembedParams) ([]vectorstore.ChunkText, error) {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	if v.Err != nil {
+		return nil, v.Err
+	}
+
+	chunks := []vectorstore.ChunkText{}
+
+	for _, path := range v.paths() {
+		document := v.documents[path]
+
+		for position, chunk := range document.params.Chunks {
+			if len(chunks) == params.Limit {
+				return chunks, nil
+			}
+
+			if document.params.EmbeddingModel == params.Model && document.params.EmbeddingDimensions == params.Dimensions {
+				continue
+			}
+
+			if staged, ok := document.staged[position]; ok && staged.model == params.Model && staged.dimensions == params.Dimensions {
+				continue
+			}
+
+			chunks = append(chunks, vectorstore.ChunkText{
+				ID:   fmt.Sprintf("%s/%d", document.id, position),
+				Text: chunk.Text,
+			})
+		}
+	}
+
+	return chunks, nil
+}
+
+func (v *FakeVectorStore) StageChunkEmbeddings(ctx context.Context, params vectorstore.StageChunkEmbeddingsParams) error {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	if v.Err != nil {
+		return v.Err
+	}
+
+	for id, embedding := range params.Embeddings {
+		documentID, positionValue, _ := strings.Cut(id, "/")
+
+		position, err := strconv.Atoi(positionValue)
+		if err != nil {
+			return fmt.Errorf("invalid chunk id '%s': %w", id, err)
+		}
+
+		document := v.documentByID(documentID)
+		if document == nil || position < 0 || position >= len(document.params.Chunks) {
+			return fmt.Errorf("unknown chunk '%s'", id)
+		}
+
+		document.staged[position] = stagedEmbedding{model: params.Model, dimensions: params.Dimensions, embedding: embedding}
+	}
+
+	return nil
+}
+
+func (v *FakeVectorStore) SwitchEmbeddingModel(ctx context.Context, model string, dimensions int) error {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+
+	if v.Err != nil {
+		return v.Err
+	}
+
+	missing := 0
+
+	for _, document := range v.documents {
+		if document.params.EmbeddingModel == model && document.params.EmbeddingDimensions == dimensions {
+			continue
+		}
+
+		for position := range document.params.Chunks {
+			if staged, ok := document.staged[position]; !ok || staged.model != model || staged.dimensions != dimensions {
+				missing++
+			}
+		}
+	}
+
+	if missing > 0 {
+		return fmt.Errorf("%w: %d chunks missing", vectorstore.ErrReembeddingIncomplete, missing)
+	}
+
+	for _, document := range v.documents {
+		if document.params.EmbeddingModel == model && document.params.EmbeddingDimensions == dimensions {
+			continue
+		}
+
+		chunks := make([]vectorstore.CreateDocumentParamsChunk, len(document.params.Chunks))
+
+		for position, chunk := range document.params.Chunks {
+			chunk.Embedding = document.staged[position].embedding
+			chunks[position] = chunk
+		}
+
+		document.params.Chunks = chunks
+		document.params.EmbeddingModel = model
+		document.params.EmbeddingDimensions = dimensions
+		document.staged = map[int]stagedEmbedding{}
+	}
+
+	v.model, v.dimensions = model, dimensions
+
+	return nil
+}
+
+// Returns the paths of all documents in order
+func (v *FakeVectorStore) paths() []string {
+	paths := make([]string, 0, len(v.documents))
+
+	for path := range v.documents {
+		paths = append(paths, path)
+	}
+
+	sort.Strings(paths)
+
+	return paths
+}
+
+func (v *FakeVectorStore) document(path string) vectorstore.Document {
+	document := v.documents[path]
+
+	return vectorstore.Document{
+		ID:          document.id,
+		FilePath:    path,
+		ContentHash: document.params.ContentHash,
+	}
+}
+
+func (v *FakeVectorStore) documentByID(id string) *fakeDocument {
+	for _, document := range v.documents {
+		if document.id == id {
+			return document
+		}
+	}
+
+	return nil
+}
+
+// Resolves the citations of the document against the other documents by court, Aktenzeichen and date
+func (v *FakeVectorStore) citations(path string, document *fakeDocument) []vectorstore.Citation {
+	citations := []vectorstore.Citation{}
+
+	for _, citation := range document.params.Citations {
+		resolved := vectorstore.Citation{
+			FilePath:     path,
+			Court:        citation.Court,
+			DecisionType: citation.DecisionType,
+			Date:         citation.Date,
+			FileNumber:   citation.FileNumber,
+			Page:         citation.Page,
+			Text:         citation.Text,
+		}
+
+		for _, citedPath := range v.paths() {
+			judgement := v.documents[citedPath].params.Judgement
+
+			if citedPath == path || judgement == nil || judgement.Court != citation.Court || judgement.FileNumber != citation.FileNumber {
+				continue
+			}
+
+			if judgement.Date != nil && citation.Date != nil && !judgement.Date.Equal(*citation.Date) {
+				continue
+			}
+
+			resolved.CitedFilePath = citedPath
+			break
+		}
+
+		citations = append(citations, resolved)
+	}
+
+	return citations
+}
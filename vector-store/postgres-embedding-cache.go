@@ -0,0 +1,117 @@
+package vectorstore
+
+import (
+	"context"
+	"time"
+
+	"github.com/JuliusMoehring/court-judgment-finder-crawler/embedder"
+	"github.com/JuliusMoehring/court-judgment-finder-crawler/logger"
+	"github.com/JuliusMoehring/court-judgment-finder-crawler/sqlc"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/pgvector/pgvector-go"
+)
+
+// Stores embeddings in the embedding_cache table of the vector store database
+type PostgresEmbeddingCache struct {
+	pool    *pgxpool.Pool
+	queries *sqlc.Queries
+
+	logger logger.Logger
+}
+
+func NewPostgresEmbeddingCache(ctx context.Context, logger logger.Logger) *PostgresEmbeddingCache {
+	pool, err := pgxpool.NewWithConfig(ctx, getConfig())
+	if err != nil {
+		panic(err)
+	}
+
+	return &PostgresEmbeddingCache{
+		pool:    pool,
+		queries: sqlc.New(pool),
+
+		logger: logger,
+	}
+}
+
+func (c *PostgresEmbeddingCache) Close() {
+	c.pool.Close()
+}
+
+func (c *PostgresEmbeddingCache) Get(ctx context.Context, keys []embedder.CacheKey) (map[embedder.CacheKey][]float32, error) {
+	type modelKey struct {
+		model      string
+		dimensions int
+		policy     string
+	}
+
+	// Keys are queried per model, usually all keys share the same model
+	hashes := map[modelKey][]string{}
+
+	for _, key := range keys {
+		model := modelKey{model: key.Model, dimensions: key.Dimensions, policy: key.Policy}
+		hashes[model] = append(hashes[model], key.TextHash)
+	}
+
+	embeddings := map[embedder.CacheKey][]float32{}
+
+	for model, textHashes := range hashes {
+		rows, err := c.queries.GetCachedEmbeddings(ctx, sqlc.GetCachedEmbeddingsParams{
+			Model:      model.model,
+			Dimensions: int32(model.dimensions),
+			Policy:     model.policy,
+			TextHashes: textHashes,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, row := range rows {
+			embeddings[embedder.CacheKey{Model: model.model, Dimensions: model.dimensions, Policy: model.policy, TextHash: row.TextHash}] = row.Embedding.Slice()
+		}
+	}
+
+	return embeddings, nil
+}
+
+func (c *PostgresEmbeddingCache) Set(ctx context.Context, entries map[embedder.CacheKey][]float32) error {
+	tx, err := c.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	queries := c.queries.WithTx(tx)
+
+	for key, embedding := range entries {
+		err := queries.CreateCachedEmbedding(ctx, sqlc.CreateCachedEmbeddingParams{
+			Model:      key.Model,
+			Dimensions: int32(key.Dimensions),
+			Policy:     key.Policy,
+			TextHash:   key.TextHash,
+			Embedding:  pgvector.NewVector(embedding),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (c *PostgresEmbeddingCache) Evict(ctx context.Context, params embedder.EvictParams) (int, error) {
+	var unusedSince *time.Time
+	if !params.UnusedSince.IsZero() {
+		unusedSince = &params.UnusedSince
+	}
+
+	evicted, err := c.queries.EvictCachedEmbeddings(ctx, sqlc.EvictCachedEmbeddingsParams{
+		UnusedSince:    timeToTimestamptz(unusedSince),
+		KeepModel:      stringToText(params.KeepModel),
+		KeepDimensions: intToInt4(params.KeepDimensions),
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return int(evicted), nil
+}
@@ -19,6 +19,52 @@ func uuidToString(uuid pgtype.UUID) string {
 	return fmt.Sprintf("%x-%x-%x-%x-%x", uuid.Bytes[0:4], uuid.Bytes[4:6], uuid.Bytes[6:8], uuid.Bytes[8:10], uuid.Bytes[10:16])
 }
 
+func stringToUUID(s string) (pgtype.UUID, error) {
+	var uuid pgtype.UUID
+	err := uuid.Scan(s)
+	return uuid, err
+}
+
+func timeToTimestamptz(t *time.Time) pgtype.Timestamptz {
+	if t == nil {
+		return pgtype.Timestamptz{}
+	}
+
+	return pgtype.Timestamptz{Time: *t, Valid: true}
+}
+
+func timeToDate(t *time.Time) pgtype.Date {
+	if t == nil {
+		return pgtype.Date{}
+	}
+
+	return pgtype.Date{Time: *t, Valid: true}
+}
+
+func pointerToBool(b *bool) pgtype.Bool {
+	if b == nil {
+		return pgtype.Bool{}
+	}
+
+	return pgtype.Bool{Bool: *b, Valid: true}
+}
+
+func stringToText(s string) pgtype.Text {
+	return pgtype.Text{String: s, Valid: s != ""}
+}
+
+func intToInt4(i int) pgtype.Int4 {
+	return pgtype.Int4{Int32: int32(i), Valid: i > 0}
+}
+
+func dateToTime(date pgtype.Date) *time.Time {
+	if !date.Valid {
+		return nil
+	}
+
+	return &date.Time
+}
+
 func getConfig() *pgxpool.Config {
 	config, err := pgxpool.ParseConfig(os.Getenv("POSTGRES_CONNECTION_STRING"))
 	if err != nil {
@@ -60,6 +106,146 @@ func (v *PostgresVectorStore) Close() {
 	v.pool.Close()
 }
 
+func (v *PostgresVectorStore) EnsureEmbeddingModel(ctx context.Context, model string, dimensions int) error {
+	tx, err := v.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	queries := v.queries.WithTx(tx)
+
+	active, err := queries.GetActiveEmbeddingModel(ctx)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return err
+	}
+
+	if err == nil && active.Model == model && int(active.Dimensions) == dimensions {
+		return nil
+	}
+
+	hasChunks, err := queries.HasChunks(ctx)
+	if err != nil {
+		return err
+	}
+
+	if hasChunks {
+		return fmt.Errorf("%w: configured %s with %d dimensions, stored %s with %d dimensions", ErrEmbeddingModelMismatch, model, dimensions, active.Model, active.Dimensions)
+	}
+
+	v.logger.Warnf("vector-store", "switching embedding model of empty store to %s with %d dimensions", model, dimensions)
+
+	if err := queries.DeactivateEmbeddingModels(ctx); err != nil {
+		return err
+	}
+
+	if err := queries.ActivateEmbeddingModel(ctx, sqlc.ActivateEmbeddingModelParams{Model: model, Dimensions: int32(dimensions)}); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (v *PostgresVectorStore) ListChunksToReembed(ctx context.Context, params ListChunksToReembedParams) ([]ChunkText, error) {
+	rows, err := v.queries.ListChunksToReembed(ctx, sqlc.ListChunksToReembedParams{
+		Model:      params.Model,
+		Dimensions: int32(params.Dimensions),
+		RowLimit:   int32(params.Limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	chunks := make([]ChunkText, 0, len(rows))
+
+	for _, row := range rows {
+		chunks = append(chunks, ChunkText{
+			ID:   uuidToString(row.ID),
+			Text: row.Text,
+		})
+	}
+
+	return chunks, nil
+}
+
+func (v *PostgresVectorStore) StageChunkEmbeddings(ctx context.Context, params StageChunkEmbeddingsParams) error {
+	tx, err := v.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	queries := v.queries.WithTx(tx)
+
+	for id, embedding := range params.Embeddings {
+		chunkID, err := stringToUUID(id)
+		if err != nil {
+			return fmt.Errorf("invalid chunk id '%s': %w", id, err)
+		}
+
+		if err := queries.CreateChunkEmbedding(ctx, sqlc.CreateChunkEmbeddingParams{
+			ChunkID:             chunkID,
+			EmbeddingModel:      params.Model,
+			EmbeddingDimensions: int32(params.Dimensions),
+			Embedding:           pgvector.NewVector(embedding),
+		}); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (v *PostgresVectorStore) SwitchEmbeddingModel(ctx context.Context, model string, dimensions int) error {
+	tx, err := v.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	queries := v.queries.WithTx(tx)
+
+	// Waits for documents being created, which share the active model, and blocks new ones until the switch is
+	// committed. Those then see the new model and are rejected if they were embedded with the previous one.
+	if err := queries.LockEmbeddingModels(ctx); err != nil {
+		return err
+	}
+
+	missing, err := queries.CountChunksToReembed(ctx, sqlc.CountChunksToReembedParams{Model: model, Dimensions: int32(dimensions)})
+	if err != nil {
+		return err
+	}
+
+	if missing > 0 {
+		return fmt.Errorf("%w: %d chunks missing", ErrReembeddingIncomplete, missing)
+	}
+
+	switched, err := queries.SwitchChunkEmbeddings(ctx, sqlc.SwitchChunkEmbeddingsParams{EmbeddingModel: model, EmbeddingDimensions: int32(dimensions)})
+	if err != nil {
+		return err
+	}
+
+	if err := queries.DeleteChunkEmbeddings(ctx, sqlc.DeleteChunkEmbeddingsParams{EmbeddingModel: model, EmbeddingDimensions: int32(dimensions)}); err != nil {
+		return err
+	}
+
+	if err := queries.DeactivateEmbeddingModels(ctx); err != nil {
+		return err
+	}
+
+	if err := queries.ActivateEmbeddingModel(ctx, sqlc.ActivateEmbeddingModelParams{Model: model, Dimensions: int32(dimensions)}); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	v.logger.Infof("vector-store", "switched %d chunks to embedding model %s with %d dimensions", switched, model, dimensions)
+
+	return nil
+}
+
 func (v *PostgresVectorStore) CreateDocument(ctx context.Context, params CreateDocumentParams) error {
 	tx, err := v.pool.Begin(ctx)
 	if err != nil {
@@ -69,17 +255,171 @@ func (v *PostgresVectorStore) CreateDocument(ctx context.Context, params CreateD
 
 	queries := v.queries.WithTx(tx)
 
-	documentID, err := queries.CreateDocument(ctx, params.FilePath)
+	// The active model cannot be switched before the document is committed, see SwitchEmbeddingModel
+	if len(params.Chunks) > 0 {
+		active, err := queries.GetActiveEmbeddingModelForShare(ctx)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return err
+		}
+
+		if err != nil || active.Model != params.EmbeddingModel || int(active.Dimensions) != params.EmbeddingDimensions {
+			return fmt.Errorf("%w: embedded with %s with %d dimensions, stored %s with %d dimensions", ErrEmbeddingModelMismatch, params.EmbeddingModel, params.EmbeddingDimensions, active.Model, active.Dimensions)
+		}
+	}
+
+	documentParams := sqlc.CreateDocumentParams{
+		FilePath:    params.FilePath,
+		ContentHash: pgtype.Text{String: params.ContentHash, Valid: params.ContentHash != ""},
+	}
+
+	if metadata := params.Metadata; metadata != nil {
+		documentParams.PageCount = pgtype.Int4{Int32: int32(metadata.PageCount), Valid: metadata.PageCount > 0}
+		documentParams.Title = pgtype.Text{String: metadata.Title, Valid: metadata.Title != ""}
+		documentParams.Producer = pgtype.Text{String: metadata.Producer, Valid: metadata.Producer != ""}
+		documentParams.PdfCreatedAt = timeToTimestamptz(metadata.CreatedAt)
+		documentParams.PdfModifiedAt = timeToTimestamptz(metadata.ModifiedAt)
+		documentParams.Encrypted = pgtype.Bool{Bool: metadata.Encrypted, Valid: true}
+		documentParams.FileSize = pgtype.Int8{Int64: int64(metadata.FileSize), Valid: metadata.FileSize > 0}
+	}
+
+	documentID, err := queries.CreateDocument(ctx, documentParams)
 	if err != nil {
 		return err
 	}
 
-	for i, page := range params.Pages {
+	if judgement := params.Judgement; judgement != nil {
+		err := queries.UpdateDocumentJudgementMetadata(ctx, sqlc.UpdateDocumentJudgementMetadataParams{
+			ID:                 documentID,
+			FileNumber:         stringToText(judgement.FileNumber),
+			DecisionDate:       timeToDate(judgement.Date),
+			DecisionType:       stringToText(judgement.DecisionType),
+			Court:              stringToText(judgement.Court),
+			Senate:             stringToText(judgement.Senate),
+			Ecli:               stringToText(judgement.ECLI),
+			Nachschlagewerk:    pointerToBool(judgement.Nachschlagewerk),
+			Bghz:               pointerToBool(judgement.BGHZ),
+			Bghr:               pointerToBool(judgement.BGHR),
+			Norms:              judgement.Norms,
+			MetadataMismatches: judgement.Mismatches,
+		})
+		if err != nil {
+			return err
+		}
+
+		// Citations of this document in documents stored before it can be resolved now
+		if judgement.Court != "" && judgement.FileNumber != "" {
+			err := queries.ResolveCitations(ctx, sqlc.ResolveCitationsParams{
+				CitedDocumentID: documentID,
+				Court:           judgement.Court,
+				FileNumber:      judgement.FileNumber,
+				DecisionDate:    timeToDate(judgement.Date),
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	// Pages are replaced as a whole, a document might have been stored with more pages or pages without text
+	if err := queries.DeleteDocumentPages(ctx, documentID); err != nil {
+		return err
+	}
+
+	for _, page := range params.Pages {
 		_, err := queries.CreateDocumentPage(ctx, sqlc.CreateDocumentPageParams{
-			Page:       int32(i + 1),
-			Text:       page.Text,
-			Embeddings: pgvector.NewVector(page.Embedding),
+			Page:             int32(page.Page),
+			Text:             page.Text,
+			DocumentID:       documentID,
+			ExtractionMethod: page.ExtractionMethod,
+			SectionTypes:     page.SectionTypes,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	// Chunks are replaced as a whole, a document might be chunked with a different strategy
+	if err := queries.DeleteDocumentChunks(ctx, documentID); err != nil {
+		return err
+	}
+
+	for i, chunk := range params.Chunks {
+		err := queries.CreateChunk(ctx, sqlc.CreateChunkParams{
+			DocumentID:          documentID,
+			Position:            int32(i),
+			Text:                chunk.Text,
+			Embeddings:          pgvector.NewVector(chunk.Embedding),
+			EmbeddingModel:      params.EmbeddingModel,
+			EmbeddingDimensions: int32(params.EmbeddingDimensions),
+			Strategy:            params.ChunkingStrategy,
+			StartOffset:         int32(chunk.StartOffset),
+			EndOffset:           int32(chunk.EndOffset),
+			StartPage:           int32(chunk.StartPage),
+			EndPage:             int32(chunk.EndPage),
+			StartMarginNumber:   intToInt4(chunk.StartMarginNumber),
+			EndMarginNumber:     intToInt4(chunk.EndMarginNumber),
+			SectionTypes:        chunk.SectionTypes,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	// Sections are replaced as a whole, a document might be split differently by a newer parser
+	if err := queries.DeleteDocumentSections(ctx, documentID); err != nil {
+		return err
+	}
+
+	for i, section := range params.Sections {
+		err := queries.CreateDocumentSection(ctx, sqlc.CreateDocumentSectionParams{
+			DocumentID:        documentID,
+			Position:          int32(i),
+			Type:              section.Type,
+			Text:              section.Text,
+			StartPage:         int32(section.StartPage),
+			EndPage:           int32(section.EndPage),
+			StartMarginNumber: pgtype.Int4{Int32: int32(section.StartMarginNumber), Valid: section.StartMarginNumber > 0},
+			EndMarginNumber:   pgtype.Int4{Int32: int32(section.EndMarginNumber), Valid: section.EndMarginNumber > 0},
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := queries.DeleteDocumentCitations(ctx, documentID); err != nil {
+		return err
+	}
+
+	for _, citation := range params.Citations {
+		err := queries.CreateCitation(ctx, sqlc.CreateCitationParams{
+			DocumentID:   documentID,
+			Court:        citation.Court,
+			DecisionType: stringToText(citation.DecisionType),
+			DecisionDate: timeToDate(citation.Date),
+			FileNumber:   citation.FileNumber,
+			Page:         int32(citation.Page),
+			Text:         citation.Text,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := queries.DeleteDocumentStatuteReferences(ctx, documentID); err != nil {
+		return err
+	}
+
+	for _, reference := range params.StatuteReferences {
+		err := queries.CreateStatuteReference(ctx, sqlc.CreateStatuteReferenceParams{
 			DocumentID: documentID,
+			Page:       int32(reference.Page),
+			Law:        reference.Law,
+			Kind:       reference.Kind,
+			Section:    reference.Section,
+			Paragraph:  intToInt4(reference.Paragraph),
+			Sentence:   intToInt4(reference.Sentence),
+			Number:     intToInt4(reference.Number),
+			Reference:  reference.Reference,
 		})
 		if err != nil {
 			return err
@@ -101,3 +441,145 @@ func (v *PostgresVectorStore) GetDocumentIDByFilePath(ctx context.Context, path
 
 	return uuidToString(uuid), nil
 }
+
+func (v *PostgresVectorStore) ListDocuments(ctx context.Context) ([]Document, error) {
+	rows, err := v.queries.ListDocuments(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	documents := make([]Document, 0, len(rows))
+
+	for _, row := range rows {
+		documents = append(documents, Document{
+			ID:          uuidToString(row.ID),
+			FilePath:    row.FilePath,
+			ContentHash: row.ContentHash.String,
+		})
+	}
+
+	return documents, nil
+}
+
+func (v *PostgresVectorStore) DeleteDocument(ctx context.Context, path string) error {
+	tx, err := v.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	queries := v.queries.WithTx(tx)
+
+	documentID, err := queries.GetDocumentIDByFilePath(ctx, path)
+	if err != nil && errors.Is(err, pgx.ErrNoRows) {
+		return ErrDocumentNotFound
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if err := queries.DeleteDocumentChunks(ctx, documentID); err != nil {
+		return err
+	}
+
+	if err := queries.DeleteDocumentSections(ctx, documentID); err != nil {
+		return err
+	}
+
+	if err := queries.DeleteDocumentCitations(ctx, documentID); err != nil {
+		return err
+	}
+
+	if err := queries.UnresolveCitations(ctx, documentID); err != nil {
+		return err
+	}
+
+	if err := queries.DeleteDocumentStatuteReferences(ctx, documentID); err != nil {
+		return err
+	}
+
+	if err := queries.DeleteDocumentPages(ctx, documentID); err != nil {
+		return err
+	}
+
+	if err := queries.DeleteDocument(ctx, documentID); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (v *PostgresVectorStore) ListCitations(ctx context.Context, path string) ([]Citation, error) {
+	rows, err := v.queries.ListCitations(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+
+	citations := make([]Citation, 0, len(rows))
+
+	for _, row := range rows {
+		citations = append(citations, Citation{
+			FilePath:      row.FilePath,
+			CitedFilePath: row.CitedFilePath.String,
+			Court:         row.Court,
+			DecisionType:  row.DecisionType.String,
+			Date:          dateToTime(row.DecisionDate),
+			FileNumber:    row.FileNumber,
+			Page:          int(row.Page),
+			Text:          row.Text,
+		})
+	}
+
+	return citations, nil
+}
+
+func (v *PostgresVectorStore) ListCitedBy(ctx context.Context, path string) ([]Citation, error) {
+	rows, err := v.queries.ListCitedBy(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+
+	citations := make([]Citation, 0, len(rows))
+
+	for _, row := range rows {
+		citations = append(citations, Citation{
+			FilePath:      row.FilePath,
+			CitedFilePath: row.CitedFilePath,
+			Court:         row.Court,
+			DecisionType:  row.DecisionType.String,
+			Date:          dateToTime(row.DecisionDate),
+			FileNumber:    row.FileNumber,
+			Page:          int(row.Page),
+			Text:          row.Text,
+		})
+	}
+
+	return citations, nil
+}
+
+func (v *PostgresVectorStore) ListDocumentsByStatute(ctx context.Context, params ListDocumentsByStatuteParams) ([]Document, error) {
+	rows, err := v.queries.ListDocumentsByStatute(ctx, sqlc.ListDocumentsByStatuteParams{
+		Law:       params.Law,
+		Kind:      params.Kind,
+		Section:   params.Section,
+		Paragraph: intToInt4(params.Paragraph),
+		Sentence:  intToInt4(params.Sentence),
+		Number:    intToInt4(params.Number),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	documents := make([]Document, 0, len(rows))
+
+	for _, row := range rows {
+		documents = append(documents, Document{
+			ID:          uuidToString(row.ID),
+			FilePath:    row.FilePath,
+			ContentHash: row.ContentHash.String,
+		})
+	}
+
+	return documents, nil
+}
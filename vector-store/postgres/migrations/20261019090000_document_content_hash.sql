@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE documents
+    ADD COLUMN IF NOT EXISTS content_hash text;
+
+CREATE INDEX IF NOT EXISTS documents_content_hash_idx ON documents (content_hash);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS documents_content_hash_idx;
+
+ALTER TABLE documents
+    DROP COLUMN IF EXISTS content_hash;
+-- +goose StatementEnd
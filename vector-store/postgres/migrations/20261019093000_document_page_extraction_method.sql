@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE document_pages
+    ADD COLUMN IF NOT EXISTS extraction_method text NOT NULL DEFAULT 'text';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE document_pages
+    DROP COLUMN IF EXISTS extraction_method;
+-- +goose StatementEnd
@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE documents
+    ADD COLUMN IF NOT EXISTS page_count      int,
+    ADD COLUMN IF NOT EXISTS title           text,
+    ADD COLUMN IF NOT EXISTS producer        text,
+    ADD COLUMN IF NOT EXISTS pdf_created_at  timestamp with time zone,
+    ADD COLUMN IF NOT EXISTS pdf_modified_at timestamp with time zone,
+    ADD COLUMN IF NOT EXISTS encrypted       boolean,
+    ADD COLUMN IF NOT EXISTS file_size       bigint;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE documents
+    DROP COLUMN IF EXISTS page_count,
+    DROP COLUMN IF EXISTS title,
+    DROP COLUMN IF EXISTS producer,
+    DROP COLUMN IF EXISTS pdf_created_at,
+    DROP COLUMN IF EXISTS pdf_modified_at,
+    DROP COLUMN IF EXISTS encrypted,
+    DROP COLUMN IF EXISTS file_size;
+-- +goose StatementEnd
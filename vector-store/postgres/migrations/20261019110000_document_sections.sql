@@ -0,0 +1,36 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS document_sections
+(
+    id                  uuid PRIMARY KEY         DEFAULT gen_random_uuid() NOT NULL,
+    document_id         uuid                                               NOT NULL,
+    position            int                                                NOT NULL,
+    type                text                                               NOT NULL,
+    text                text                                               NOT NULL,
+    start_page          int                                                NOT NULL,
+    end_page            int                                                NOT NULL,
+    start_margin_number int,
+    end_margin_number   int,
+    created_at          timestamp with time zone DEFAULT CURRENT_TIMESTAMP,
+    updated_at          timestamp with time zone DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (document_id) REFERENCES documents (id),
+    UNIQUE (document_id, position)
+);
+
+CREATE INDEX IF NOT EXISTS document_sections_type_idx ON document_sections (type);
+
+ALTER TABLE document_pages
+    ADD COLUMN IF NOT EXISTS section_types text[] NOT NULL DEFAULT '{}';
+
+CREATE INDEX IF NOT EXISTS document_pages_section_types_idx ON document_pages USING gin (section_types);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS document_pages_section_types_idx;
+
+ALTER TABLE document_pages
+    DROP COLUMN IF EXISTS section_types;
+
+DROP TABLE IF EXISTS document_sections;
+-- +goose StatementEnd
@@ -0,0 +1,37 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE documents
+    ADD COLUMN IF NOT EXISTS file_number         text,
+    ADD COLUMN IF NOT EXISTS decision_date       date,
+    ADD COLUMN IF NOT EXISTS decision_type       text,
+    ADD COLUMN IF NOT EXISTS court               text,
+    ADD COLUMN IF NOT EXISTS senate              text,
+    ADD COLUMN IF NOT EXISTS ecli                text,
+    ADD COLUMN IF NOT EXISTS nachschlagewerk     boolean,
+    ADD COLUMN IF NOT EXISTS bghz                boolean,
+    ADD COLUMN IF NOT EXISTS bghr                boolean,
+    ADD COLUMN IF NOT EXISTS norms               text[],
+    ADD COLUMN IF NOT EXISTS metadata_mismatches text[];
+
+CREATE INDEX IF NOT EXISTS documents_file_number_idx ON documents (file_number);
+CREATE INDEX IF NOT EXISTS documents_ecli_idx ON documents (ecli);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS documents_ecli_idx;
+DROP INDEX IF EXISTS documents_file_number_idx;
+
+ALTER TABLE documents
+    DROP COLUMN IF EXISTS file_number,
+    DROP COLUMN IF EXISTS decision_date,
+    DROP COLUMN IF EXISTS decision_type,
+    DROP COLUMN IF EXISTS court,
+    DROP COLUMN IF EXISTS senate,
+    DROP COLUMN IF EXISTS ecli,
+    DROP COLUMN IF EXISTS nachschlagewerk,
+    DROP COLUMN IF EXISTS bghz,
+    DROP COLUMN IF EXISTS bghr,
+    DROP COLUMN IF EXISTS norms,
+    DROP COLUMN IF EXISTS metadata_mismatches;
+-- +goose StatementEnd
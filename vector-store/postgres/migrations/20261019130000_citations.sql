@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS citations
+(
+    id                uuid PRIMARY KEY         DEFAULT gen_random_uuid() NOT NULL,
+    document_id       uuid                                               NOT NULL,
+    cited_document_id uuid,
+    court             text                                               NOT NULL,
+    decision_type     text,
+    decision_date     date,
+    file_number       text                                               NOT NULL,
+    page              int                                                NOT NULL,
+    text              text                                               NOT NULL,
+    created_at        timestamp with time zone DEFAULT CURRENT_TIMESTAMP,
+    updated_at        timestamp with time zone DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (document_id) REFERENCES documents (id),
+    FOREIGN KEY (cited_document_id) REFERENCES documents (id),
+    UNIQUE (document_id, page, court, file_number)
+);
+
+CREATE INDEX IF NOT EXISTS citations_cited_document_id_idx ON citations (cited_document_id);
+CREATE INDEX IF NOT EXISTS citations_court_file_number_idx ON citations (court, file_number);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS citations;
+-- +goose StatementEnd
@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS statute_references
+(
+    id          uuid PRIMARY KEY         DEFAULT gen_random_uuid() NOT NULL,
+    document_id uuid                                               NOT NULL,
+    page        int                                                NOT NULL,
+    law         text                                               NOT NULL,
+    kind        text                                               NOT NULL,
+    section     text                                               NOT NULL,
+    paragraph   int,
+    sentence    int,
+    number      int,
+    reference   text                                               NOT NULL,
+    created_at  timestamp with time zone DEFAULT CURRENT_TIMESTAMP,
+    updated_at  timestamp with time zone DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (document_id) REFERENCES documents (id),
+    UNIQUE (document_id, page, reference)
+);
+
+CREATE INDEX IF NOT EXISTS statute_references_norm_idx ON statute_references (law, kind, section);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS statute_references;
+-- +goose StatementEnd
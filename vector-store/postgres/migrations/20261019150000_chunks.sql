@@ -0,0 +1,65 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS chunks
+(
+    id                  uuid PRIMARY KEY         DEFAULT gen_random_uuid() NOT NULL,
+    document_id         uuid                                               NOT NULL,
+    position            int                                                NOT NULL,
+    text                text                                               NOT NULL,
+    embeddings          vector(1536)                                       NOT NULL,
+    strategy            text                                               NOT NULL,
+    start_offset        int                                                NOT NULL,
+    end_offset          int                                                NOT NULL,
+    start_page          int                                                NOT NULL,
+    end_page            int                                                NOT NULL,
+    start_margin_number int,
+    end_margin_number   int,
+    section_types       text[]                                             NOT NULL DEFAULT '{}',
+    created_at          timestamp with time zone DEFAULT CURRENT_TIMESTAMP,
+    updated_at          timestamp with time zone DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (document_id) REFERENCES documents (id),
+    UNIQUE (document_id, position)
+);
+
+CREATE INDEX IF NOT EXISTS chunks_section_types_idx ON chunks USING gin (section_types);
+
+-- Documents stored before keep their page embeddings as one chunk per page, so they remain searchable until
+-- they are processed again. The offsets assume pages separated by form feeds like the chunkers do.
+INSERT INTO chunks (document_id, position, text, embeddings, strategy, start_offset, end_offset, start_page, end_page,
+                    section_types)
+SELECT document_id,
+       ROW_NUMBER() OVER (PARTITION BY document_id ORDER BY page) - 1,
+       text,
+       embeddings,
+       'pages',
+       COALESCE(SUM(OCTET_LENGTH(text) + 1)
+                OVER (PARTITION BY document_id ORDER BY page ROWS BETWEEN UNBOUNDED PRECEDING AND 1 PRECEDING), 0),
+       COALESCE(SUM(OCTET_LENGTH(text) + 1)
+                OVER (PARTITION BY document_id ORDER BY page ROWS BETWEEN UNBOUNDED PRECEDING AND 1 PRECEDING), 0) +
+       OCTET_LENGTH(text),
+       page,
+       page,
+       section_types
+FROM document_pages
+WHERE embeddings IS NOT NULL
+  AND text != ''
+ON CONFLICT (document_id, position) DO NOTHING;
+
+-- Pages keep their text, the embeddings are stored per chunk
+ALTER TABLE document_pages
+    ALTER COLUMN embeddings DROP NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE
+FROM document_pages
+WHERE embeddings IS NULL;
+
+ALTER TABLE document_pages
+    ALTER COLUMN embeddings SET NOT NULL;
+
+DROP INDEX IF EXISTS chunks_section_types_idx;
+
+DROP TABLE IF EXISTS chunks;
+-- +goose StatementEnd
@@ -0,0 +1,48 @@
+-- +goose Up
+-- +goose StatementBegin
+-- The dimensions depend on the configured model, every embedding records the model it was created with
+ALTER TABLE chunks
+    ALTER COLUMN embeddings TYPE vector;
+
+ALTER TABLE chunks
+    ADD COLUMN IF NOT EXISTS embedding_model      text NOT NULL DEFAULT 'text-embedding-ada-002',
+    ADD COLUMN IF NOT EXISTS embedding_dimensions int  NOT NULL DEFAULT 1536;
+
+ALTER TABLE chunks
+    ALTER COLUMN embedding_model DROP DEFAULT,
+    ALTER COLUMN embedding_dimensions DROP DEFAULT,
+    ADD CONSTRAINT chunks_embedding_dimensions_check CHECK (vector_dims(embeddings) = embedding_dimensions);
+
+-- The model all stored embeddings are created with, only one model is active at a time
+CREATE TABLE IF NOT EXISTS embedding_models
+(
+    model      text                                         NOT NULL,
+    dimensions int                                          NOT NULL CHECK (dimensions > 0),
+    active     boolean                  DEFAULT FALSE       NOT NULL,
+    created_at timestamp with time zone DEFAULT CURRENT_TIMESTAMP,
+    updated_at timestamp with time zone DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (model, dimensions)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS embedding_models_active_idx ON embedding_models (active) WHERE active;
+
+INSERT INTO embedding_models (model, dimensions, active)
+VALUES ('text-embedding-ada-002', 1536, TRUE);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS embedding_models;
+
+ALTER TABLE chunks
+    DROP CONSTRAINT IF EXISTS chunks_embedding_dimensions_check,
+    DROP COLUMN IF EXISTS embedding_dimensions,
+    DROP COLUMN IF EXISTS embedding_model;
+
+DELETE
+FROM chunks
+WHERE vector_dims(embeddings) != 1536;
+
+ALTER TABLE chunks
+    ALTER COLUMN embeddings TYPE vector(1536);
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE IF NOT EXISTS embedding_cache
+(
+    model        text                                        NOT NULL,
+    dimensions   int                                         NOT NULL,
+    text_hash    text                                        NOT NULL,
+    embedding    vector                                      NOT NULL,
+    created_at   timestamp with time zone DEFAULT CURRENT_TIMESTAMP,
+    last_used_at timestamp with time zone DEFAULT CURRENT_TIMESTAMP NOT NULL,
+    PRIMARY KEY (model, dimensions, text_hash),
+    CHECK (vector_dims(embedding) = dimensions)
+);
+
+CREATE INDEX IF NOT EXISTS embedding_cache_last_used_at_idx ON embedding_cache (last_used_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS embedding_cache;
+-- +goose StatementEnd
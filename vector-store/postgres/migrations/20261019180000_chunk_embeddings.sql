@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Embeddings of another model are staged here while re-embedding until they replace the active ones
+CREATE TABLE IF NOT EXISTS chunk_embeddings
+(
+    chunk_id             uuid                                         NOT NULL,
+    embedding_model      text                                         NOT NULL,
+    embedding_dimensions int                                          NOT NULL,
+    embedding            vector                                       NOT NULL,
+    created_at           timestamp with time zone DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (chunk_id, embedding_model, embedding_dimensions),
+    FOREIGN KEY (chunk_id) REFERENCES chunks (id) ON DELETE CASCADE,
+    CHECK (vector_dims(embedding) = embedding_dimensions)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS chunk_embeddings;
+-- +goose StatementEnd
@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Embeddings of texts exceeding the context window depend on how they were split or truncated. Entries cached
+-- before are kept under the empty policy of embedders without a context window.
+ALTER TABLE embedding_cache
+    ADD COLUMN IF NOT EXISTS policy text NOT NULL DEFAULT '';
+
+ALTER TABLE embedding_cache
+    DROP CONSTRAINT IF EXISTS embedding_cache_pkey,
+    ADD PRIMARY KEY (model, dimensions, policy, text_hash);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE
+FROM embedding_cache
+WHERE policy != '';
+
+ALTER TABLE embedding_cache
+    DROP CONSTRAINT IF EXISTS embedding_cache_pkey,
+    ADD PRIMARY KEY (model, dimensions, text_hash);
+
+ALTER TABLE embedding_cache
+    DROP COLUMN IF EXISTS policy;
+-- +goose StatementEnd
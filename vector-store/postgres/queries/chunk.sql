@@ -0,0 +1,10 @@
+-- name: CreateChunk :exec
+INSERT INTO chunks (document_id, position, text, embeddings, embedding_model, embedding_dimensions, strategy,
+                    start_offset, end_offset, start_page, end_page, start_margin_number, end_margin_number,
+                    section_types)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14);
+
+-- name: DeleteDocumentChunks :exec
+DELETE
+FROM chunks
+WHERE document_id = $1;
@@ -0,0 +1,44 @@
+-- name: ListChunksToReembed :many
+SELECT c.id, c.text
+FROM chunks c
+WHERE NOT (c.embedding_model = @model AND c.embedding_dimensions = @dimensions)
+  AND NOT EXISTS (SELECT 1
+                  FROM chunk_embeddings ce
+                  WHERE ce.chunk_id = c.id
+                    AND ce.embedding_model = @model
+                    AND ce.embedding_dimensions = @dimensions)
+ORDER BY c.id
+LIMIT @row_limit;
+
+-- name: CountChunksToReembed :one
+SELECT count(*)
+FROM chunks c
+WHERE NOT (c.embedding_model = @model AND c.embedding_dimensions = @dimensions)
+  AND NOT EXISTS (SELECT 1
+                  FROM chunk_embeddings ce
+                  WHERE ce.chunk_id = c.id
+                    AND ce.embedding_model = @model
+                    AND ce.embedding_dimensions = @dimensions);
+
+-- name: CreateChunkEmbedding :exec
+INSERT INTO chunk_embeddings (chunk_id, embedding_model, embedding_dimensions, embedding)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (chunk_id, embedding_model, embedding_dimensions) DO UPDATE
+    SET embedding = EXCLUDED.embedding;
+
+-- name: SwitchChunkEmbeddings :execrows
+UPDATE chunks c
+SET embeddings           = ce.embedding,
+    embedding_model      = ce.embedding_model,
+    embedding_dimensions = ce.embedding_dimensions,
+    updated_at           = CURRENT_TIMESTAMP
+FROM chunk_embeddings ce
+WHERE ce.chunk_id = c.id
+  AND ce.embedding_model = $1
+  AND ce.embedding_dimensions = $2;
+
+-- name: DeleteChunkEmbeddings :exec
+DELETE
+FROM chunk_embeddings
+WHERE embedding_model = $1
+  AND embedding_dimensions = $2;
@@ -0,0 +1,61 @@
+-- name: CreateCitation :exec
+INSERT INTO citations (document_id, court, decision_type, decision_date, file_number, page, text, cited_document_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7,
+        (SELECT id
+         FROM documents
+         WHERE documents.court = $2
+           AND documents.file_number = $5
+           AND (documents.decision_date IS NULL OR documents.decision_date = $4)
+           AND documents.id <> $1
+         LIMIT 1));
+
+-- name: DeleteDocumentCitations :exec
+DELETE
+FROM citations
+WHERE document_id = $1;
+
+-- name: ResolveCitations :exec
+UPDATE citations
+SET cited_document_id = @cited_document_id,
+    updated_at        = CURRENT_TIMESTAMP
+WHERE cited_document_id IS NULL
+  AND document_id <> @cited_document_id
+  AND court = @court
+  AND file_number = @file_number
+  AND (sqlc.narg('decision_date')::date IS NULL OR decision_date = sqlc.narg('decision_date'));
+
+-- name: UnresolveCitations :exec
+UPDATE citations
+SET cited_document_id = NULL,
+    updated_at        = CURRENT_TIMESTAMP
+WHERE cited_document_id = $1;
+
+-- name: ListCitations :many
+SELECT citations.court,
+       citations.decision_type,
+       citations.decision_date,
+       citations.file_number,
+       citations.page,
+       citations.text,
+       documents.file_path,
+       cited_documents.file_path AS cited_file_path
+FROM citations
+         JOIN documents ON documents.id = citations.document_id
+         LEFT JOIN documents cited_documents ON cited_documents.id = citations.cited_document_id
+WHERE documents.file_path = $1
+ORDER BY citations.page, citations.file_number;
+
+-- name: ListCitedBy :many
+SELECT citations.court,
+       citations.decision_type,
+       citations.decision_date,
+       citations.file_number,
+       citations.page,
+       citations.text,
+       documents.file_path,
+       cited_documents.file_path AS cited_file_path
+FROM citations
+         JOIN documents ON documents.id = citations.document_id
+         JOIN documents cited_documents ON cited_documents.id = citations.cited_document_id
+WHERE cited_documents.file_path = $1
+ORDER BY documents.file_path, citations.page;
@@ -1,11 +1,46 @@
 -- name: CreateDocument :one
-INSERT INTO documents (file_path)
-VALUES ($1)
+INSERT INTO documents (file_path, content_hash, page_count, title, producer, pdf_created_at, pdf_modified_at,
+                       encrypted, file_size)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 ON CONFLICT (file_path) DO UPDATE
-    SET updated_at = CURRENT_TIMESTAMP
+    SET content_hash    = $2,
+        page_count      = $3,
+        title           = $4,
+        producer        = $5,
+        pdf_created_at  = $6,
+        pdf_modified_at = $7,
+        encrypted       = $8,
+        file_size       = $9,
+        updated_at      = CURRENT_TIMESTAMP
 RETURNING id;
 
 -- name: GetDocumentIDByFilePath :one
 SELECT id
 FROM documents
-WHERE file_path = $1;
\ No newline at end of file
+WHERE file_path = $1;
+
+-- name: ListDocuments :many
+SELECT id, file_path, content_hash
+FROM documents
+ORDER BY file_path;
+
+-- name: DeleteDocument :exec
+DELETE
+FROM documents
+WHERE id = $1;
+
+-- name: UpdateDocumentJudgementMetadata :exec
+UPDATE documents
+SET file_number         = $2,
+    decision_date       = $3,
+    decision_type       = $4,
+    court               = $5,
+    senate              = $6,
+    ecli                = $7,
+    nachschlagewerk     = $8,
+    bghz                = $9,
+    bghr                = $10,
+    norms               = $11,
+    metadata_mismatches = $12,
+    updated_at          = CURRENT_TIMESTAMP
+WHERE id = $1;
\ No newline at end of file
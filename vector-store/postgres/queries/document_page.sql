@@ -1,8 +1,16 @@
 -- name: CreateDocumentPage :one
-INSERT INTO document_pages (page, text, embeddings, document_id)
-VALUES ($1, $2, $3, $4)
+INSERT INTO document_pages (page, text, document_id, extraction_method, section_types)
+VALUES ($1, $2, $3, $4, $5)
 ON CONFLICT (document_id, page) DO UPDATE
-    SET document_id = $4,
-        page        = $1,
-        updated_at  = CURRENT_TIMESTAMP
-RETURNING id;
\ No newline at end of file
+    SET document_id       = $3,
+        page              = $1,
+        text              = $2,
+        extraction_method = $4,
+        section_types     = $5,
+        updated_at        = CURRENT_TIMESTAMP
+RETURNING id;
+
+-- name: DeleteDocumentPages :exec
+DELETE
+FROM document_pages
+WHERE document_id = $1;
\ No newline at end of file
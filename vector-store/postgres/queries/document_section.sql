@@ -0,0 +1,9 @@
+-- name: CreateDocumentSection :exec
+INSERT INTO document_sections (document_id, position, type, text, start_page, end_page, start_margin_number,
+                               end_margin_number)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8);
+
+-- name: DeleteDocumentSections :exec
+DELETE
+FROM document_sections
+WHERE document_id = $1;
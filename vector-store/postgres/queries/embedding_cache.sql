@@ -0,0 +1,21 @@
+-- name: GetCachedEmbeddings :many
+UPDATE embedding_cache
+SET last_used_at = CURRENT_TIMESTAMP
+WHERE model = @model
+  AND dimensions = @dimensions
+  AND policy = @policy
+  AND text_hash = ANY (@text_hashes::text[])
+RETURNING text_hash, embedding;
+
+-- name: CreateCachedEmbedding :exec
+INSERT INTO embedding_cache (model, dimensions, policy, text_hash, embedding)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (model, dimensions, policy, text_hash) DO UPDATE
+    SET last_used_at = CURRENT_TIMESTAMP;
+
+-- name: EvictCachedEmbeddings :execrows
+DELETE
+FROM embedding_cache
+WHERE (sqlc.narg('unused_since')::timestamptz IS NOT NULL AND last_used_at < sqlc.narg('unused_since'))
+   OR (sqlc.narg('keep_model')::text IS NOT NULL AND model != sqlc.narg('keep_model'))
+   OR (sqlc.narg('keep_dimensions')::int IS NOT NULL AND dimensions != sqlc.narg('keep_dimensions'));
@@ -0,0 +1,29 @@
+-- name: GetActiveEmbeddingModel :one
+SELECT model, dimensions
+FROM embedding_models
+WHERE active;
+
+-- name: GetActiveEmbeddingModelForShare :one
+SELECT model, dimensions
+FROM embedding_models
+WHERE active
+FOR SHARE;
+
+-- name: LockEmbeddingModels :exec
+LOCK TABLE embedding_models IN EXCLUSIVE MODE;
+
+-- name: DeactivateEmbeddingModels :exec
+UPDATE embedding_models
+SET active     = FALSE,
+    updated_at = CURRENT_TIMESTAMP
+WHERE active;
+
+-- name: ActivateEmbeddingModel :exec
+INSERT INTO embedding_models (model, dimensions, active)
+VALUES ($1, $2, TRUE)
+ON CONFLICT (model, dimensions) DO UPDATE
+    SET active     = TRUE,
+        updated_at = CURRENT_TIMESTAMP;
+
+-- name: HasChunks :one
+SELECT EXISTS (SELECT 1 FROM chunks);
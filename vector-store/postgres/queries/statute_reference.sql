@@ -0,0 +1,20 @@
+-- name: CreateStatuteReference :exec
+INSERT INTO statute_references (document_id, page, law, kind, section, paragraph, sentence, number, reference)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9);
+
+-- name: DeleteDocumentStatuteReferences :exec
+DELETE
+FROM statute_references
+WHERE document_id = $1;
+
+-- name: ListDocumentsByStatute :many
+SELECT DISTINCT documents.id, documents.file_path, documents.content_hash
+FROM statute_references
+         JOIN documents ON documents.id = statute_references.document_id
+WHERE statute_references.law = @law
+  AND statute_references.kind = @kind
+  AND statute_references.section = @section
+  AND (sqlc.narg('paragraph')::int IS NULL OR statute_references.paragraph = sqlc.narg('paragraph'))
+  AND (sqlc.narg('sentence')::int IS NULL OR statute_references.sentence = sqlc.narg('sentence'))
+  AND (sqlc.narg('number')::int IS NULL OR statute_references.number = sqlc.narg('number'))
+ORDER BY documents.file_path;
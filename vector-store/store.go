@@ -3,23 +3,200 @@ package vectorstore
 import (
 	"context"
 	"errors"
+	"time"
 )
 
 type CreateDocumentParamsPage struct {
+	// Number of the page in the PDF, starting at 1
+	Page int
+	Text string
+	// How the text was extracted from the PDF, e.g. "text" or "ocr"
+	ExtractionMethod string
+	// Types of the judgement sections on the page, e.g. "tenor" or "gruende"
+	SectionTypes []string
+}
+
+type CreateDocumentParamsSection struct {
+	// Type of the section, e.g. "leitsatz" or "tatbestand"
+	Type      string
+	Text      string
+	StartPage int
+	EndPage   int
+	// Randnummern within the section, zero if the section has none
+	StartMarginNumber int
+	EndMarginNumber   int
+}
+
+// Part of the document that is embedded on its own
+type CreateDocumentParamsChunk struct {
 	Text      string
 	Embedding []float32
+	// Byte offsets in the normalized text of the document
+	StartOffset int
+	EndOffset   int
+	StartPage   int
+	EndPage     int
+	// Randnummern the chunk spans, zero before the first Randnummer of the document
+	StartMarginNumber int
+	EndMarginNumber   int
+	// Types of the judgement sections on the pages of the chunk
+	SectionTypes []string
+}
+
+// Metadata of the PDF, zero values are stored as unknown
+type DocumentMetadata struct {
+	PageCount  int
+	Title      string
+	Producer   string
+	CreatedAt  *time.Time
+	ModifiedAt *time.Time
+	Encrypted  bool
+	FileSize   int
+}
+
+// Reference to another decision, resolved against the stored documents by court, Aktenzeichen and date
+type CreateDocumentParamsCitation struct {
+	Court        string
+	DecisionType string
+	Date         *time.Time
+	FileNumber   string
+	Page         int
+	Text         string
+}
+
+// Normalized reference to a statute, e.g. "§ 5 Abs. 1 UWG"
+type CreateDocumentParamsStatuteReference struct {
+	Page    int
+	Law     string
+	Kind    string
+	Section string
+	// Absatz, Satz and Nummer, zero if not referenced
+	Paragraph int
+	Sentence  int
+	Number    int
+	Reference string
+}
+
+// Metadata read from the header of a judgement, zero values are stored as unknown
+type JudgementMetadata struct {
+	FileNumber   string
+	Date         *time.Time
+	DecisionType string
+	Court        string
+	Senate       string
+	ECLI         string
+
+	Nachschlagewerk *bool
+	BGHZ            *bool
+	BGHR            *bool
+
+	Norms []string
+	// Differences between the metadata and what the crawler found
+	Mismatches []string
 }
 
 type CreateDocumentParams struct {
 	FilePath string
-	Pages    []CreateDocumentParamsPage
+	// SHA-256 hash of the stored file, empty if unknown
+	ContentHash string
+	Metadata    *DocumentMetadata
+	Judgement   *JudgementMetadata
+	Pages       []CreateDocumentParamsPage
+	// Chunking strategy the chunks were created with, e.g. "tokens" or "margin-numbers"
+	ChunkingStrategy string
+	// Model and dimensions the embeddings of the chunks were created with
+	EmbeddingModel      string
+	EmbeddingDimensions int
+	// Chunks in the order they appear in the document
+	Chunks []CreateDocumentParamsChunk
+	// Sections in the order they appear in the document
+	Sections          []CreateDocumentParamsSection
+	Citations         []CreateDocumentParamsCitation
+	StatuteReferences []CreateDocumentParamsStatuteReference
+}
+
+type Document struct {
+	ID          string
+	FilePath    string
+	ContentHash string
+}
+
+type Citation struct {
+	// File path of the citing document
+	FilePath string
+	// File path of the cited document, empty if the cited decision is not stored
+	CitedFilePath string
+	Court         string
+	DecisionType  string
+	Date          *time.Time
+	FileNumber    string
+	// Page of the citing document
+	Page int
+	Text string
+}
+
+// Zero subdivisions match any subdivision, e.g. "§ 5 UWG" matches "§ 5 Abs. 1 Satz 2 UWG"
+type ListDocumentsByStatuteParams struct {
+	Law       string
+	Kind      string
+	Section   string
+	Paragraph int
+	Sentence  int
+	Number    int
+}
+
+// Text of a chunk to embed with another model
+type ChunkText struct {
+	ID   string
+	Text string
 }
 
-var ErrDocumentNotFound = errors.New("document not found")
+type ListChunksToReembedParams struct {
+	Model      string
+	Dimensions int
+	Limit      int
+}
+
+type StageChunkEmbeddingsParams struct {
+	Model      string
+	Dimensions int
+	// Embeddings by chunk ID
+	Embeddings map[string][]float32
+}
+
+var (
+	ErrDocumentNotFound       = errors.New("document not found")
+	ErrEmbeddingModelMismatch = errors.New("embedding model does not match the stored embeddings")
+	ErrReembeddingIncomplete  = errors.New("not all chunks are embedded with the new model")
+)
 
 type VectorStore interface {
 	Close()
 
+	// Checks that the stored embeddings were created with the model and dimensions and returns an
+	// ErrEmbeddingModelMismatch error otherwise. A store without embeddings switches to the model.
+	EnsureEmbeddingModel(ctx context.Context, model string, dimensions int) error
+	// Returns chunks that are neither embedded nor staged with the model and dimensions
+	ListChunksToReembed(ctx context.Context, params ListChunksToReembedParams) ([]ChunkText, error)
+	// Stores embeddings of another model next to the active ones until SwitchEmbeddingModel replaces them
+	StageChunkEmbeddings(ctx context.Context, params StageChunkEmbeddingsParams) error
+	// Atomically replaces the embeddings of all chunks with the staged ones and activates the model. Returns an
+	// ErrReembeddingIncomplete error if a chunk has no staged embedding.
+	SwitchEmbeddingModel(ctx context.Context, model string, dimensions int) error
+
+	// Creates or replaces the document. Returns an ErrEmbeddingModelMismatch error if the chunks are not embedded
+	// with the active model, e.g. because the model was switched while the document was processed.
 	CreateDocument(ctx context.Context, params CreateDocumentParams) error
 	GetDocumentIDByFilePath(ctx context.Context, path string) (string, error)
+	ListDocuments(ctx context.Context) ([]Document, error)
+	// Deletes the document with the given file path including all of its pages, chunks, sections and citations
+	DeleteDocument(ctx context.Context, path string) error
+
+	// Returns the decisions cited by the document with the given file path
+	ListCitations(ctx context.Context, path string) ([]Citation, error)
+	// Returns the citations of the document with the given file path in other documents
+	ListCitedBy(ctx context.Context, path string) ([]Citation, error)
+
+	// Returns all documents referencing the statute
+	ListDocumentsByStatute(ctx context.Context, params ListDocumentsByStatuteParams) ([]Document, error)
 }
@@ -5,7 +5,10 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/JuliusMoehring/court-judgment-finder-crawler/logger"
 	"github.com/surrealdb/surrealdb.go"
@@ -52,37 +55,424 @@ func (v *SurrealDBVectorStore) Close() {
 	v.db.Close()
 }
 
+func (v *SurrealDBVectorStore) EnsureEmbeddingModel(ctx context.Context, model string, dimensions int) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	type result struct {
+		Model      string `json:"model"`
+		Dimensions int    `json:"dimensions"`
+	}
+
+	active, err := marshal.SmartUnmarshal[result](v.db.Query("SELECT model, dimensions FROM embeddingModel:active;", map[string]string{}))
+	if err != nil {
+		return err
+	}
+
+	if len(active) == 1 && active[0].Model == model && active[0].Dimensions == dimensions {
+		return nil
+	}
+
+	chunks, err := marshal.SmartUnmarshal[string](v.db.Query("SELECT VALUE id FROM chunk LIMIT 1;", map[string]string{}))
+	if err != nil {
+		return err
+	}
+
+	if len(chunks) > 0 {
+		stored := result{}
+		if len(active) == 1 {
+			stored = active[0]
+		}
+
+		return fmt.Errorf("%w: configured %s with %d dimensions, stored %s with %d dimensions", ErrEmbeddingModelMismatch, model, dimensions, stored.Model, stored.Dimensions)
+	}
+
+	v.logger.Warnf("vector-store", "switching embedding model of empty store to %s with %d dimensions", model, dimensions)
+
+	// The dimension of the index cannot be a parameter
+	response, err := v.db.Query(fmt.Sprintf(`
+		BEGIN TRANSACTION;
+
+		UPDATE embeddingModel:active CONTENT { model: $model, dimensions: $dimensions };
+
+		REMOVE INDEX IF EXISTS mTreeEmbeddingCosineIndex ON chunk;
+		DEFINE INDEX mTreeEmbeddingCosineIndex ON chunk FIELDS embedding MTREE DIMENSION %d DIST COSINE TYPE F64 CAPACITY 40 DOC_IDS_ORDER 100 DOC_IDS_CACHE 100 MTREE_CACHE 100;
+
+		COMMIT TRANSACTION;`, dimensions),
+		map[string]interface{}{
+			"model":      model,
+			"dimensions": dimensions,
+		})
+	if err != nil {
+		v.logger.Errorf("vector-store", "failed to switch embedding model to %s.", model)
+		return err
+	}
+
+	var queryResult []marshal.RawQuery[any]
+
+	if err := marshal.UnmarshalRaw(response, &queryResult); err != nil {
+		v.logger.Errorf("vector-store", "failed to unmarshal response for embedding model %s: %s", model, err)
+		return err
+	}
+
+	for _, result := range queryResult {
+		if result.Status != marshal.StatusOK {
+			return errors.New(fmt.Sprintf("failed to switch embedding model to %s: %s", model, result.Detail))
+		}
+	}
+
+	return nil
+}
+
+// Chunk IDs are the ID of the document and the position of the chunk separated by a slash
+func (v *SurrealDBVectorStore) ListChunksToReembed(ctx context.Context, params ListChunksToReembedParams) ([]ChunkText, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	type result struct {
+		Document string `json:"document"`
+		Position int    `json:"position"`
+		Text     string `json:"text"`
+	}
+
+	results, err := marshal.SmartUnmarshal[result](v.db.Query(`
+		SELECT meta::id(id[0]) AS document, position, text FROM chunk
+		WHERE !(embeddingModel = $model AND embeddingDimensions = $dimensions)
+			AND !(stagedEmbeddingModel = $model AND stagedEmbeddingDimensions = $dimensions)
+		LIMIT $limit;`,
+		map[string]interface{}{
+			"model":      params.Model,
+			"dimensions": params.Dimensions,
+			"limit":      params.Limit,
+		}))
+	if err != nil {
+		return nil, err
+	}
+
+	chunks := make([]ChunkText, 0, len(results))
+
+	for _, result := range results {
+		chunks = append(chunks, ChunkText{
+			ID:   fmt.Sprintf("%s/%d", result.Document, result.Position),
+			Text: result.Text,
+		})
+	}
+
+	return chunks, nil
+}
+
+func (v *SurrealDBVectorStore) StageChunkEmbeddings(ctx context.Context, params StageChunkEmbeddingsParams) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	type staged struct {
+		Document  string    `json:"document"`
+		Position  int       `json:"position"`
+		Embedding []float32 `json:"embedding"`
+	}
+
+	embeddings := make([]staged, 0, len(params.Embeddings))
+
+	for id, embedding := range params.Embeddings {
+		separator := strings.LastIndex(id, "/")
+		if separator < 0 {
+			return fmt.Errorf("invalid chunk id '%s'", id)
+		}
+
+		position, err := strconv.Atoi(id[separator+1:])
+		if err != nil {
+			return fmt.Errorf("invalid chunk id '%s': %w", id, err)
+		}
+
+		embeddings = append(embeddings, staged{Document: id[:separator], Position: position, Embedding: embedding})
+	}
+
+	response, err := v.db.Query(`
+		BEGIN TRANSACTION;
+
+		FOR $staged IN $embeddings {
+			UPDATE type::thing('chunk', [type::thing('document', $staged.document), $staged.position])
+				SET stagedEmbedding = $staged.embedding, stagedEmbeddingModel = $model, stagedEmbeddingDimensions = $dimensions;
+		};
+
+		COMMIT TRANSACTION;`,
+		map[string]interface{}{
+			"embeddings": embeddings,
+			"model":      params.Model,
+			"dimensions": params.Dimensions,
+		})
+	if err != nil {
+		v.logger.Errorf("vector-store", "failed to stage embeddings of %s.", params.Model)
+		return err
+	}
+
+	var queryResult []marshal.RawQuery[any]
+
+	if err := marshal.UnmarshalRaw(response, &queryResult); err != nil {
+		v.logger.Errorf("vector-store", "failed to unmarshal response for staged embeddings of %s: %s", params.Model, err)
+		return err
+	}
+
+	for _, result := range queryResult {
+		if result.Status != marshal.StatusOK {
+			return errors.New(fmt.Sprintf("failed to stage embeddings of %s: %s", params.Model, result.Detail))
+		}
+	}
+
+	return nil
+}
+
+func (v *SurrealDBVectorStore) SwitchEmbeddingModel(ctx context.Context, model string, dimensions int) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	variables := map[string]interface{}{
+		"model":      model,
+		"dimensions": dimensions,
+	}
+
+	missing, err := marshal.SmartUnmarshal[string](v.db.Query(`
+		SELECT VALUE id FROM chunk
+		WHERE !(embeddingModel = $model AND embeddingDimensions = $dimensions)
+			AND !(stagedEmbeddingModel = $model AND stagedEmbeddingDimensions = $dimensions)
+		LIMIT 1;`, variables))
+	if err != nil {
+		return err
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: %s", ErrReembeddingIncomplete, missing[0])
+	}
+
+	// The dimension of the index cannot be a parameter
+	response, err := v.db.Query(fmt.Sprintf(`
+		BEGIN TRANSACTION;
+
+		UPDATE chunk
+			SET embedding = stagedEmbedding, embeddingModel = $model, embeddingDimensions = $dimensions,
+				stagedEmbedding = NONE, stagedEmbeddingModel = NONE, stagedEmbeddingDimensions = NONE
+			WHERE stagedEmbeddingModel = $model AND stagedEmbeddingDimensions = $dimensions;
+
+		UPDATE embeddingModel:active CONTENT { model: $model, dimensions: $dimensions };
+
+		REMOVE INDEX IF EXISTS mTreeEmbeddingCosineIndex ON chunk;
+		DEFINE INDEX mTreeEmbeddingCosineIndex ON chunk FIELDS embedding MTREE DIMENSION %d DIST COSINE TYPE F64 CAPACITY 40 DOC_IDS_ORDER 100 DOC_IDS_CACHE 100 MTREE_CACHE 100;
+
+		COMMIT TRANSACTION;`, dimensions),
+		variables)
+	if err != nil {
+		v.logger.Errorf("vector-store", "failed to switch embedding model to %s.", model)
+		return err
+	}
+
+	var queryResult []marshal.RawQuery[any]
+
+	if err := marshal.UnmarshalRaw(response, &queryResult); err != nil {
+		v.logger.Errorf("vector-store", "failed to unmarshal response for embedding model %s: %s", model, err)
+		return err
+	}
+
+	for _, result := range queryResult {
+		if result.Status != marshal.StatusOK {
+			return errors.New(fmt.Sprintf("failed to switch embedding model to %s: %s", model, result.Detail))
+		}
+	}
+
+	return nil
+}
+
 func (v *SurrealDBVectorStore) CreateDocument(ctx context.Context, params CreateDocumentParams) error {
 	v.mu.Lock()
 	defer v.mu.Unlock()
 
+	if len(params.Chunks) > 0 {
+		type result struct {
+			Model      string `json:"model"`
+			Dimensions int    `json:"dimensions"`
+		}
+
+		active, err := marshal.SmartUnmarshal[result](v.db.Query("SELECT model, dimensions FROM embeddingModel:active;", map[string]string{}))
+		if err != nil {
+			return err
+		}
+
+		stored := result{}
+		if len(active) == 1 {
+			stored = active[0]
+		}
+
+		if stored.Model != params.EmbeddingModel || stored.Dimensions != params.EmbeddingDimensions {
+			return fmt.Errorf("%w: embedded with %s with %d dimensions, stored %s with %d dimensions", ErrEmbeddingModelMismatch, params.EmbeddingModel, params.EmbeddingDimensions, stored.Model, stored.Dimensions)
+		}
+	}
+
 	type page struct {
-		Page      int       `json:"page"`
-		Text      string    `json:"text"`
-		Embedding []float32 `json:"embedding"`
+		Page             int      `json:"page"`
+		Text             string   `json:"text"`
+		ExtractionMethod string   `json:"extractionMethod"`
+		SectionTypes     []string `json:"sectionTypes"`
+	}
+
+	type chunk struct {
+		Position            int       `json:"position"`
+		Text                string    `json:"text"`
+		Embedding           []float32 `json:"embedding"`
+		EmbeddingModel      string    `json:"embeddingModel"`
+		EmbeddingDimensions int       `json:"embeddingDimensions"`
+		Strategy            string    `json:"strategy"`
+		StartOffset         int       `json:"startOffset"`
+		EndOffset           int       `json:"endOffset"`
+		StartPage           int       `json:"startPage"`
+		EndPage             int       `json:"endPage"`
+		StartMarginNumber   int       `json:"startMarginNumber"`
+		EndMarginNumber     int       `json:"endMarginNumber"`
+		SectionTypes        []string  `json:"sectionTypes"`
+	}
+
+	type section struct {
+		Position          int    `json:"position"`
+		Type              string `json:"type"`
+		Text              string `json:"text"`
+		StartPage         int    `json:"startPage"`
+		EndPage           int    `json:"endPage"`
+		StartMarginNumber int    `json:"startMarginNumber"`
+		EndMarginNumber   int    `json:"endMarginNumber"`
+	}
+
+	type citation struct {
+		Position     int        `json:"position"`
+		Court        string     `json:"court"`
+		DecisionType string     `json:"decisionType,omitempty"`
+		Date         *time.Time `json:"date,omitempty"`
+		FileNumber   string     `json:"fileNumber"`
+		Page         int        `json:"page"`
+		Text         string     `json:"text"`
+	}
+
+	type statuteReference struct {
+		Page      int    `json:"page"`
+		Law       string `json:"law"`
+		Kind      string `json:"kind"`
+		Section   string `json:"section"`
+		Paragraph int    `json:"paragraph,omitempty"`
+		Sentence  int    `json:"sentence,omitempty"`
+		Number    int    `json:"number,omitempty"`
+		Reference string `json:"reference"`
 	}
 
 	var pages []page
 
-	for i, p := range params.Pages {
+	for _, p := range params.Pages {
 		pages = append(pages, page{
-			Page:      i + 1,
-			Text:      p.Text,
-			Embedding: p.Embedding,
+			Page:             p.Page,
+			Text:             p.Text,
+			ExtractionMethod: p.ExtractionMethod,
+			SectionTypes:     p.SectionTypes,
+		})
+	}
+
+	chunks := []chunk{}
+
+	for i, c := range params.Chunks {
+		chunks = append(chunks, chunk{
+			Position:            i,
+			Text:                c.Text,
+			Embedding:           c.Embedding,
+			EmbeddingModel:      params.EmbeddingModel,
+			EmbeddingDimensions: params.EmbeddingDimensions,
+			Strategy:            params.ChunkingStrategy,
+			StartOffset:         c.StartOffset,
+			EndOffset:           c.EndOffset,
+			StartPage:           c.StartPage,
+			EndPage:             c.EndPage,
+			StartMarginNumber:   c.StartMarginNumber,
+			EndMarginNumber:     c.EndMarginNumber,
+			SectionTypes:        c.SectionTypes,
+		})
+	}
+
+	sections := []section{}
+
+	for i, s := range params.Sections {
+		sections = append(sections, section{
+			Position:          i,
+			Type:              s.Type,
+			Text:              s.Text,
+			StartPage:         s.StartPage,
+			EndPage:           s.EndPage,
+			StartMarginNumber: s.StartMarginNumber,
+			EndMarginNumber:   s.EndMarginNumber,
+		})
+	}
+
+	citations := []citation{}
+
+	for i, c := range params.Citations {
+		citations = append(citations, citation{
+			Position:     i,
+			Court:        c.Court,
+			DecisionType: c.DecisionType,
+			Date:         c.Date,
+			FileNumber:   c.FileNumber,
+			Page:         c.Page,
+			Text:         c.Text,
+		})
+	}
+
+	statuteReferences := []statuteReference{}
+
+	for _, r := range params.StatuteReferences {
+		statuteReferences = append(statuteReferences, statuteReference{
+			Page:      r.Page,
+			Law:       r.Law,
+			Kind:      r.Kind,
+			Section:   r.Section,
+			Paragraph: r.Paragraph,
+			Sentence:  r.Sentence,
+			Number:    r.Number,
+			Reference: r.Reference,
 		})
 	}
 
 	response, err := v.db.Query(`
 		BEGIN TRANSACTION;
 
-		LET $doc = (CREATE ONLY document SET filePath = $filePath);
+		-- An existing document is replaced as a whole but keeps its id, so citations of it stay resolved
+		LET $existing = (SELECT VALUE id FROM ONLY document WHERE filePath = $filePath LIMIT 1);
+
+		DELETE page WHERE id[0] = $existing;
+		DELETE chunk WHERE id[0] = $existing;
+		DELETE section WHERE id[0] = $existing;
+		DELETE citation WHERE document = $existing;
+		DELETE statute WHERE document = $existing;
+
+		LET $doc = IF $existing THEN
+			(UPDATE ONLY $existing SET contentHash = $contentHash, metadata = $metadata, judgement = $judgement)
+		ELSE
+			(CREATE ONLY document SET filePath = $filePath, contentHash = $contentHash, metadata = $metadata, judgement = $judgement)
+		END;
 
 		INSERT INTO page (SELECT *, [$doc.id, page] AS id FROM $pages);
+		INSERT INTO chunk (SELECT *, [$doc.id, position] AS id FROM $chunks);
+		INSERT INTO section (SELECT *, [$doc.id, position] AS id FROM $sections);
+		INSERT INTO citation (SELECT *, [$doc.id, position] AS id, $doc.id AS document, (SELECT VALUE id FROM document WHERE judgement.Court = $parent.court AND judgement.FileNumber = $parent.fileNumber AND id != $doc.id LIMIT 1)[0] AS citedDocument FROM $citations);
+
+		-- Citations of this document in documents stored before it can be resolved now
+		UPDATE citation SET citedDocument = $doc.id WHERE citedDocument = NONE AND document != $doc.id AND court = $judgement.Court AND fileNumber = $judgement.FileNumber;
+
+		INSERT INTO statute (SELECT *, $doc.id AS document FROM $statuteReferences);
 
 		COMMIT TRANSACTION;`,
 		map[string]interface{}{
-			"filePath": params.FilePath,
-			"pages":    pages,
+			"filePath":          params.FilePath,
+			"contentHash":       params.ContentHash,
+			"metadata":          params.Metadata,
+			"judgement":         params.Judgement,
+			"pages":             pages,
+			"chunks":            chunks,
+			"sections":          sections,
+			"citations":         citations,
+			"statuteReferences": statuteReferences,
 		})
 	if err != nil {
 		v.logger.Errorf("vector-store", "failed to create document for path '%s'.", params.FilePath)
@@ -124,3 +514,173 @@ func (v *SurrealDBVectorStore) GetDocumentIDByFilePath(ctx context.Context, path
 
 	return ids[0].ID, nil
 }
+
+func (v *SurrealDBVectorStore) ListDocuments(ctx context.Context) ([]Document, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	type result struct {
+		ID          string `json:"id"`
+		FilePath    string `json:"filePath"`
+		ContentHash string `json:"contentHash"`
+	}
+
+	results, err := marshal.SmartUnmarshal[result](v.db.Query("SELECT id, filePath, contentHash FROM document ORDER BY filePath;", map[string]string{}))
+	if err != nil {
+		return nil, err
+	}
+
+	documents := make([]Document, 0, len(results))
+
+	for _, result := range results {
+		documents = append(documents, Document{
+			ID:          result.ID,
+			FilePath:    result.FilePath,
+			ContentHash: result.ContentHash,
+		})
+	}
+
+	return documents, nil
+}
+
+func (v *SurrealDBVectorStore) DeleteDocument(ctx context.Context, path string) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	response, err := v.db.Query(`
+		BEGIN TRANSACTION;
+
+		LET $doc = (SELECT VALUE id FROM ONLY document WHERE filePath = $path LIMIT 1);
+
+		DELETE page WHERE id[0] = $doc;
+		DELETE chunk WHERE id[0] = $doc;
+		DELETE section WHERE id[0] = $doc;
+		DELETE citation WHERE document = $doc;
+		UPDATE citation SET citedDocument = NONE WHERE citedDocument = $doc;
+		DELETE statute WHERE document = $doc;
+		DELETE $doc;
+
+		COMMIT TRANSACTION;`,
+		map[string]interface{}{
+			"path": path,
+		})
+	if err != nil {
+		v.logger.Errorf("vector-store", "failed to delete document for path '%s'.", path)
+		return err
+	}
+
+	var queryResult []marshal.RawQuery[any]
+
+	if err := marshal.UnmarshalRaw(response, &queryResult); err != nil {
+		v.logger.Errorf("vector-store", "failed to unmarshal response for path '%s': %s", path, err)
+		return err
+	}
+
+	for _, result := range queryResult {
+		if result.Status != marshal.StatusOK {
+			return errors.New(fmt.Sprintf("failed to delete document for path '%s': %s", path, result.Detail))
+		}
+	}
+
+	return nil
+}
+
+func (v *SurrealDBVectorStore) listCitations(where string, path string) ([]Citation, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	type result struct {
+		FilePath      string     `json:"filePath"`
+		CitedFilePath string     `json:"citedFilePath"`
+		Court         string     `json:"court"`
+		DecisionType  string     `json:"decisionType"`
+		Date          *time.Time `json:"date"`
+		FileNumber    string     `json:"fileNumber"`
+		Page          int        `json:"page"`
+		Text          string     `json:"text"`
+	}
+
+	results, err := marshal.SmartUnmarshal[result](v.db.Query(`
+		SELECT document.filePath AS filePath, citedDocument.filePath AS citedFilePath, court, decisionType, date, fileNumber, page, text
+		FROM citation
+		WHERE `+where+` = $path
+		ORDER BY filePath, page, fileNumber;`,
+		map[string]string{"path": path}))
+	if err != nil {
+		return nil, err
+	}
+
+	citations := make([]Citation, 0, len(results))
+
+	for _, result := range results {
+		citations = append(citations, Citation{
+			FilePath:      result.FilePath,
+			CitedFilePath: result.CitedFilePath,
+			Court:         result.Court,
+			DecisionType:  result.DecisionType,
+			Date:          result.Date,
+			FileNumber:    result.FileNumber,
+			Page:          result.Page,
+			Text:          result.Text,
+		})
+	}
+
+	return citations, nil
+}
+
+func (v *SurrealDBVectorStore) ListCitations(ctx context.Context, path string) ([]Citation, error) {
+	return v.listCitations("document.filePath", path)
+}
+
+func (v *SurrealDBVectorStore) ListCitedBy(ctx context.Context, path string) ([]Citation, error) {
+	return v.listCitations("citedDocument.filePath", path)
+}
+
+func (v *SurrealDBVectorStore) ListDocumentsByStatute(ctx context.Context, params ListDocumentsByStatuteParams) ([]Document, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	type result struct {
+		ID          string `json:"id"`
+		FilePath    string `json:"filePath"`
+		ContentHash string `json:"contentHash"`
+	}
+
+	// Zero subdivisions match any subdivision
+	results, err := marshal.SmartUnmarshal[result](v.db.Query(`
+		SELECT id, filePath, contentHash
+		FROM array::distinct((
+			SELECT VALUE document
+			FROM statute
+			WHERE law = $law
+				AND kind = $kind
+				AND section = $section
+				AND ($paragraph = 0 OR paragraph = $paragraph)
+				AND ($sentence = 0 OR sentence = $sentence)
+				AND ($number = 0 OR number = $number)
+		))
+		ORDER BY filePath;`,
+		map[string]interface{}{
+			"law":       params.Law,
+			"kind":      params.Kind,
+			"section":   params.Section,
+			"paragraph": params.Paragraph,
+			"sentence":  params.Sentence,
+			"number":    params.Number,
+		}))
+	if err != nil {
+		return nil, err
+	}
+
+	documents := make([]Document, 0, len(results))
+
+	for _, result := range results {
+		documents = append(documents, Document{
+			ID:          result.ID,
+			FilePath:    result.FilePath,
+			ContentHash: result.ContentHash,
+		})
+	}
+
+	return documents, nil
+}